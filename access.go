@@ -0,0 +1,36 @@
+package memai
+
+import (
+	"context"
+	"time"
+)
+
+// AccessEvent records that a memory was returned by LTM.Search.
+type AccessEvent[ID comparable] struct {
+	MemoryID  ID
+	Namespace Namespace
+	Time      time.Time
+	Rank      int    // 0-based position in the search results
+	Query     string // SearchQuery.Query (empty when searching by embedding)
+}
+
+// recordAccess queues one access event per result, if tracking is enabled.
+func (l *LTM[ID]) recordAccess(ctx context.Context, ns Namespace, query string, results []SearchResult[ID]) {
+	if l.accessStore == nil || len(results) == 0 {
+		return
+	}
+	now := l.now()
+	events := make([]AccessEvent[ID], len(results))
+	for i, r := range results {
+		events[i] = AccessEvent[ID]{
+			MemoryID:  r.Memory.ID,
+			Namespace: ns,
+			Time:      now,
+			Rank:      i,
+			Query:     query,
+		}
+	}
+	l.async.enqueue(ctx, func(ctx context.Context) error {
+		return l.accessStore.RecordAccess(ctx, events)
+	})
+}
@@ -0,0 +1,75 @@
+package memai
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// accessStore is a mockStore that also implements AccessStore.
+type accessStore struct {
+	mockStore
+	mu     sync.Mutex
+	events []AccessEvent[int]
+}
+
+func (s *accessStore) RecordAccess(_ context.Context, events []AccessEvent[int]) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, events...)
+	return nil
+}
+
+func TestLTM_TrackAccess(t *testing.T) {
+	store := &accessStore{mockStore: mockStore{
+		memories: []Memory[int]{
+			{ID: 1, Embedding: []float64{1, 0}},
+			{ID: 2, Embedding: []float64{0.9, 0.1}},
+			{ID: 3, Embedding: []float64{0, 1}},
+		},
+	}}
+	now := time.Date(2026, 6, 17, 12, 0, 0, 0, time.UTC)
+	cfg := DefaultLTMConfig()
+	cfg.TrackAccess = true
+	cfg.Clock = func() time.Time { return now }
+	ltm := NewLTM[int](store, nil, cfg)
+
+	if _, err := ltm.Search(context.Background(), SearchQuery{Query: "q", QueryEmbedding: []float64{1, 0}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ltm.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(store.events) != 2 {
+		t.Fatalf("expected 2 access events, got %d", len(store.events))
+	}
+	for i, ev := range store.events {
+		if ev.Rank != i || ev.Query != "q" || !ev.Time.Equal(now) {
+			t.Errorf("unexpected event %d: %+v", i, ev)
+		}
+	}
+	if store.events[0].MemoryID != 1 {
+		t.Errorf("expected top hit first, got %d", store.events[0].MemoryID)
+	}
+
+	// Search keeps working after Close, without recording.
+	if _, err := ltm.Search(context.Background(), SearchQuery{QueryEmbedding: []float64{1, 0}}); err != nil {
+		t.Fatalf("unexpected error after Close: %v", err)
+	}
+}
+
+func TestLTM_TrackAccessDisabled(t *testing.T) {
+	store := &accessStore{mockStore: mockStore{
+		memories: []Memory[int]{{ID: 1, Embedding: []float64{1, 0}}},
+	}}
+	ltm := NewLTM[int](store, nil, DefaultLTMConfig())
+	if _, err := ltm.Search(context.Background(), SearchQuery{QueryEmbedding: []float64{1, 0}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ltm.Close()
+	if len(store.events) != 0 {
+		t.Errorf("access must not be recorded unless TrackAccess is set, got %d events", len(store.events))
+	}
+}
@@ -0,0 +1,95 @@
+package memai
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrAccessQueueFull is reported to LTMConfig.AccessErrorHandler when access
+// events or co-recall links are dropped because the background writer cannot
+// keep up. Search never blocks on these writes.
+var ErrAccessQueueFull = errors.New("memai: access queue full, events dropped")
+
+// asyncTask is one deferred store write plus the context values (namespace
+// etc.) it was issued under.
+type asyncTask struct {
+	ctx context.Context
+	fn  func(context.Context) error
+}
+
+// asyncWriter runs store writes that are a side effect of Search on a single
+// background goroutine, so they never add to search latency.
+type asyncWriter struct {
+	onErr func(error)
+	queue chan asyncTask
+	done  chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func newAsyncWriter(size int, onErr func(error)) *asyncWriter {
+	if size <= 0 {
+		size = 256
+	}
+	w := &asyncWriter{
+		onErr: onErr,
+		queue: make(chan asyncTask, size),
+		done:  make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+func (w *asyncWriter) run() {
+	defer close(w.done)
+	for t := range w.queue {
+		if err := t.fn(t.ctx); err != nil {
+			w.report(err)
+		}
+	}
+}
+
+// enqueue hands fn to the background goroutine without blocking. The task is
+// dropped if the queue is full or the writer is closed. ctx keeps its values
+// but not its cancellation, since the search will have returned by then.
+func (w *asyncWriter) enqueue(ctx context.Context, fn func(context.Context) error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return
+	}
+	select {
+	case w.queue <- asyncTask{ctx: context.WithoutCancel(ctx), fn: fn}:
+	default:
+		w.report(ErrAccessQueueFull)
+	}
+}
+
+// close stops accepting tasks and waits until queued ones have run.
+func (w *asyncWriter) close() {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.mu.Unlock()
+	<-w.done
+}
+
+func (w *asyncWriter) report(err error) {
+	if w.onErr != nil {
+		w.onErr(err)
+	}
+}
+
+// Close flushes pending background writes and stops the background
+// goroutine. It is a no-op when no background feature is enabled. Search
+// remains usable after Close but no longer records access or co-recall links.
+func (l *LTM[ID]) Close() error {
+	if l.async != nil {
+		l.async.close()
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package memai
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CheckpointVersion is the version of the Checkpoint encodings. Decoding
+// accepts this version and older ones.
+const CheckpointVersion = 1
+
+// checkpointMagic starts every binary checkpoint.
+const checkpointMagic = "MEMAICP"
+
+// ErrCheckpointFormat is returned when checkpoint data is corrupt or from a
+// newer version.
+var ErrCheckpointFormat = errors.New("memai: unsupported checkpoint format")
+
+// PendingFeedback is what the next turn needs to turn the user's reaction
+// ("そうそう", "that's wrong") into LTM.ApplyFeedback: the memories the last
+// reply drew on.
+type PendingFeedback[ID comparable] struct {
+	MemoryIDs []ID   `json:"memory_ids"`
+	Turn      int    `json:"turn"`            // Turn of the reply that used them
+	Query     string `json:"query,omitempty"` // Search query that found them
+}
+
+// Checkpoint is the per-conversation state of an agent between turns:
+// working memory, the current turn, the STM configuration, the latest
+// emotional state and pending feedback. Long-term memories live in the
+// MemoryStore and are not included. Save a checkpoint after every turn and
+// restore it in the next process to resume the conversation.
+type Checkpoint[ID comparable] struct {
+	Version   int                  `json:"version"`
+	SavedAt   time.Time            `json:"saved_at"`
+	Turn      int                  `json:"turn"`
+	STMConfig STMConfig            `json:"stm_config"`
+	Items     []WorkingMemoryItem  `json:"items"`
+	Emotion   *EmotionalState      `json:"emotion,omitempty"`
+	Feedback  *PendingFeedback[ID] `json:"feedback,omitempty"`
+}
+
+// NewCheckpoint captures stm together with the latest emotional state and
+// pending feedback (either may be nil). The checkpoint holds copies, so
+// later changes to stm do not affect it.
+func NewCheckpoint[ID comparable](stm *STM, emotion *EmotionalState, feedback *PendingFeedback[ID]) *Checkpoint[ID] {
+	snap := stm.Snapshot()
+	c := &Checkpoint[ID]{
+		Version:   CheckpointVersion,
+		SavedAt:   time.Now().UTC(),
+		Turn:      snap.Turn,
+		STMConfig: snap.Config,
+		Items:     snap.Items,
+	}
+	if emotion != nil {
+		e := *emotion
+		c.Emotion = &e
+	}
+	if feedback != nil {
+		f := *feedback
+		f.MemoryIDs = append([]ID(nil), feedback.MemoryIDs...)
+		c.Feedback = &f
+	}
+	return c
+}
+
+// RestoreSTM returns a new STM with the checkpointed configuration, items
+// and turn. Plugins are not encoded in checkpoints; set them on STMConfig
+// before restoring a decoded checkpoint.
+func (c *Checkpoint[ID]) RestoreSTM() *STM {
+	stm, _ := NewSTMFromSnapshot(STMSnapshot{
+		Version: STMSnapshotVersion,
+		Turn:    c.Turn,
+		Config:  c.STMConfig,
+		Items:   c.Items,
+	})
+	return stm
+}
+
+// MarshalBinary encodes the checkpoint compactly with gob, behind a magic
+// string and version byte.
+func (c *Checkpoint[ID]) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(checkpointMagic)
+	buf.WriteByte(CheckpointVersion)
+	// Encode without the methods, or gob would call MarshalBinary again.
+	type plain Checkpoint[ID]
+	p := plain(*c)
+	p.STMConfig = p.STMConfig.withoutPlugins()
+	if err := gob.NewEncoder(&buf).Encode(&p); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a checkpoint written by MarshalBinary.
+func (c *Checkpoint[ID]) UnmarshalBinary(data []byte) error {
+	rest, ok := bytes.CutPrefix(data, []byte(checkpointMagic))
+	if !ok || len(rest) == 0 {
+		return fmt.Errorf("%w: missing header", ErrCheckpointFormat)
+	}
+	if v := int(rest[0]); v < 1 || v > CheckpointVersion {
+		return fmt.Errorf("%w: version %d", ErrCheckpointFormat, v)
+	}
+	type plain Checkpoint[ID]
+	var decoded plain
+	if err := gob.NewDecoder(bytes.NewReader(rest[1:])).Decode(&decoded); err != nil {
+		return fmt.Errorf("%w: %v", ErrCheckpointFormat, err)
+	}
+	*c = Checkpoint[ID](decoded)
+	return nil
+}
+
+// UnmarshalJSON decodes a checkpoint encoded with encoding/json, rejecting
+// unknown versions.
+func (c *Checkpoint[ID]) UnmarshalJSON(data []byte) error {
+	type plain Checkpoint[ID]
+	var decoded plain
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if decoded.Version < 1 || decoded.Version > CheckpointVersion {
+		return fmt.Errorf("%w: version %d", ErrCheckpointFormat, decoded.Version)
+	}
+	*c = Checkpoint[ID](decoded)
+	return nil
+}
@@ -0,0 +1,100 @@
+package memai
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func checkpointFixture() (*STM, *Checkpoint[int]) {
+	cfg := DefaultSTMConfig()
+	cfg.MaxItems = 5
+	stm := NewSTM(cfg)
+	stm.SetItems([]*WorkingMemoryItem{
+		{Topic: "旅行", Keywords: []string{"京都"}, Activation: 0.9, TurnCreated: 1, TurnAccessed: 1},
+		{Topic: "仕事", Keywords: []string{"締切"}, Activation: 0.6, TurnCreated: 2, TurnAccessed: 2},
+	})
+	stm.Update(3, "京都が楽しみ", &EmotionalState{Primary: EmotionJoy, Intensity: 0.7, Valence: 0.6})
+	cp := NewCheckpoint(stm, &EmotionalState{Primary: EmotionJoy, Intensity: 0.7, Valence: 0.6},
+		&PendingFeedback[int]{MemoryIDs: []int{4, 9}, Turn: 3, Query: "京都"})
+	return stm, cp
+}
+
+func TestCheckpoint_CapturesCopy(t *testing.T) {
+	stm, cp := checkpointFixture()
+	if cp.Turn != 3 || cp.STMConfig.MaxItems != 5 || len(cp.Items) != 2 {
+		t.Fatalf("unexpected checkpoint: %+v", cp)
+	}
+	activation := cp.Items[0].Activation
+	stm.Items()[0].Keywords[0] = "changed"
+	stm.Update(10, "", nil)
+	if cp.Items[0].Keywords[0] != "京都" || cp.Items[0].Activation != activation {
+		t.Errorf("checkpoint should not follow later STM changes: %+v", cp.Items[0])
+	}
+}
+
+func TestCheckpoint_BinaryAndJSONRoundTrip(t *testing.T) {
+	_, cp := checkpointFixture()
+
+	bin, err := cp.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var fromBin Checkpoint[int]
+	if err := fromBin.UnmarshalBinary(bin); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	js, err := json.Marshal(cp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var fromJSON Checkpoint[int]
+	if err := json.Unmarshal(js, &fromJSON); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for name, got := range map[string]Checkpoint[int]{"binary": fromBin, "json": fromJSON} {
+		if !got.SavedAt.Equal(cp.SavedAt) {
+			t.Errorf("%s: SavedAt %v, want %v", name, got.SavedAt, cp.SavedAt)
+		}
+		got.SavedAt = cp.SavedAt
+		if !reflect.DeepEqual(&got, cp) {
+			t.Errorf("%s round trip differs:\n got %+v\nwant %+v", name, got, *cp)
+		}
+	}
+}
+
+func TestCheckpoint_RestoreSTMResumes(t *testing.T) {
+	original, cp := checkpointFixture()
+	restored := cp.RestoreSTM()
+	if restored.Turn() != 3 || !reflect.DeepEqual(restored.Config(), original.Config()) {
+		t.Fatalf("unexpected restored STM: turn %d config %+v", restored.Turn(), restored.Config())
+	}
+
+	original.Update(5, "締切の話", nil)
+	restored.Update(5, "締切の話", nil)
+	a, b := original.Items(), restored.Items()
+	if len(a) != len(b) {
+		t.Fatalf("restored STM diverged: %d vs %d items", len(a), len(b))
+	}
+	for i := range a {
+		if !reflect.DeepEqual(*a[i], *b[i]) {
+			t.Errorf("item %d diverged: %+v vs %+v", i, *a[i], *b[i])
+		}
+	}
+}
+
+func TestCheckpoint_RejectsBadData(t *testing.T) {
+	var cp Checkpoint[int]
+	if err := cp.UnmarshalBinary([]byte("garbage")); !errors.Is(err, ErrCheckpointFormat) {
+		t.Errorf("expected ErrCheckpointFormat, got %v", err)
+	}
+	if err := cp.UnmarshalBinary([]byte(checkpointMagic + "\x63")); !errors.Is(err, ErrCheckpointFormat) {
+		t.Errorf("expected ErrCheckpointFormat for a newer version, got %v", err)
+	}
+	if err := json.Unmarshal([]byte(`{"version":99}`), &cp); !errors.Is(err, ErrCheckpointFormat) {
+		t.Errorf("expected ErrCheckpointFormat, got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package memai
+
+import "math"
+
+// DecayInput is what a DecayFunc knows about an item at a decay step.
+//
+// Decay amounts are measured in load: elapsed turns times the per-turn rate
+// plus elapsed hours times the hourly rate. Emotional items accumulate load
+// at the emotional rates, so emotional modulation applies the same way to
+// every DecayFunc.
+type DecayInput struct {
+	Activation    float64 // Activation before this step
+	Strength      float64 // Activation when the item was last added or refreshed
+	Elapsed       float64 // Load of this step
+	Age           float64 // Load since the item was last added or refreshed, including Elapsed
+	Lifetime      float64 // Load since the item was added, including Elapsed
+	Presentations int     // Times the item was added or refreshed (at least 1)
+	Emotional     bool
+}
+
+// DecayFunc returns an item's activation after a decay step. STM clamps the
+// result at 0. Set STMConfig.DecayFunc to one of the built-ins or your own.
+type DecayFunc func(in DecayInput) float64
+
+// LinearDecay subtracts the load: activation falls by the configured rates
+// per turn and per hour. This is the default.
+func LinearDecay(in DecayInput) float64 {
+	return in.Activation - in.Elapsed
+}
+
+// ExponentialDecay multiplies activation by e^-load, so an item loses the
+// same fraction of its activation per turn and never quite reaches zero on
+// its own.
+func ExponentialDecay(in DecayInput) float64 {
+	return in.Activation * math.Exp(-in.Elapsed)
+}
+
+// PowerLawDecay returns a DecayFunc following the power law of forgetting:
+// Strength × (1 + Age)^-exponent. Items fade quickly at first and then ever
+// more slowly. A refresh restarts the curve from the boosted activation.
+func PowerLawDecay(exponent float64) DecayFunc {
+	return func(in DecayInput) float64 {
+		return in.Strength * math.Pow(1+in.Age, -exponent)
+	}
+}
+
+// ACTRDecay returns a DecayFunc computing ACT-R base-level activation with
+// decay d (0.5 in ACT-R), using the optimized-learning approximation
+// B = ln(n / (1-d)) - d·ln(L) for n presentations over lifetime L, mapped to
+// 0..1 with the logistic function. Each refresh counts as a presentation.
+// The result never exceeds the current activation, so decay cannot revive
+// an item added with low activation.
+//
+// Base-level activation falls slowly: with d = 0.5 an item presented once
+// drops below 0.5 after a load of 4 (about 27 turns at the default
+// NormalDecayRate) but below 0.1 only after a load of about 320. Pair it
+// with an ActivationThreshold around 0.5, or rely on MaxItems, for items to
+// be evicted within a conversation.
+func ACTRDecay(d float64) DecayFunc {
+	return func(in DecayInput) float64 {
+		n := float64(max(in.Presentations, 1))
+		b := math.Log(n/(1-d)) - d*math.Log(in.Lifetime)
+		return min(in.Activation, 1/(1+math.Exp(-b)))
+	}
+}
+
+// primeDecayState initialises the decay state of an item that has none, as
+// if it had just been added with its current activation.
+func primeDecayState(item *WorkingMemoryItem) {
+	if item.Presentations == 0 {
+		item.Presentations = 1
+		item.Strength = item.Activation
+		item.Age = 0
+	}
+}
@@ -0,0 +1,137 @@
+package memai
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDecayFuncs(t *testing.T) {
+	in := DecayInput{Activation: 0.8, Strength: 1.0, Elapsed: 0.3, Age: 0.6, Lifetime: 1.5, Presentations: 2}
+	tests := []struct {
+		name string
+		fn   DecayFunc
+		want float64
+	}{
+		{"linear", LinearDecay, 0.5},
+		{"exponential", ExponentialDecay, 0.8 * math.Exp(-0.3)},
+		{"power-law", PowerLawDecay(1), 1 / 1.6},
+		{"act-r", ACTRDecay(0.5), 1 / (1 + math.Exp(-(math.Log(4) - 0.5*math.Log(1.5))))},
+	}
+	for _, tt := range tests {
+		if got := tt.fn(in); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: got %f, want %f", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDecayFuncsNeverIncreaseActivation(t *testing.T) {
+	fns := map[string]DecayFunc{
+		"linear": LinearDecay, "exponential": ExponentialDecay,
+		"power-law": PowerLawDecay(0.5), "act-r": ACTRDecay(0.5),
+	}
+	for name, fn := range fns {
+		config := DefaultSTMConfig()
+		config.DecayFunc = fn
+		config.ActivationThreshold = 0
+		stm := NewSTM(config)
+		stm.SetItems([]*WorkingMemoryItem{
+			{Topic: "weak", Keywords: []string{"a"}, Activation: 0.2},
+			{Topic: "strong", Keywords: []string{"b"}, Activation: 1.0},
+		})
+		prev := []float64{0.2, 1.0}
+		for turn := 1; turn <= 50; turn++ {
+			stm.Update(turn, "zzz", nil)
+			for i, it := range stm.Items() {
+				if it.Activation > prev[i]+1e-12 {
+					t.Fatalf("%s: %s rose from %f to %f at turn %d", name, it.Topic, prev[i], it.Activation, turn)
+				}
+				prev[i] = it.Activation
+			}
+		}
+	}
+}
+
+func TestSTM_ACTREvictsWithThreshold(t *testing.T) {
+	config := DefaultSTMConfig()
+	config.DecayFunc = ACTRDecay(0.5)
+	config.ActivationThreshold = 0.5
+	stm := NewSTM(config)
+	stm.SetItems([]*WorkingMemoryItem{{Topic: "A", Keywords: []string{"a"}, Activation: 1.0}})
+	for turn := 1; turn <= 30; turn++ {
+		stm.Update(turn, "zzz", nil)
+	}
+	if items := stm.Items(); len(items) != 0 {
+		t.Errorf("item should be evicted after a load above 4, got %+v", items)
+	}
+}
+
+func TestSTM_DecayFuncShapesForgetting(t *testing.T) {
+	activation := func(fn DecayFunc, turns int) float64 {
+		config := DefaultSTMConfig()
+		config.DecayFunc = fn
+		config.ActivationThreshold = 0
+		stm := NewSTM(config)
+		stm.SetItems([]*WorkingMemoryItem{{Topic: "A", Keywords: []string{"a"}, Activation: 1.0}})
+		for turn := 1; turn <= turns; turn++ {
+			stm.Update(turn, "zzz", nil)
+		}
+		return stm.Items()[0].Activation
+	}
+
+	if got := activation(nil, 4); math.Abs(got-0.4) > 1e-6 {
+		t.Errorf("default should stay linear: got %f, want 0.4", got)
+	}
+	// Exponential decay is memoryless: four steps of 0.15 load equal one of 0.6.
+	if got := activation(ExponentialDecay, 4); math.Abs(got-math.Exp(-0.6)) > 1e-6 {
+		t.Errorf("exponential: got %f, want %f", got, math.Exp(-0.6))
+	}
+	// Power-law decay runs from the strength at the last refresh.
+	if got := activation(PowerLawDecay(1), 4); math.Abs(got-1/1.6) > 1e-6 {
+		t.Errorf("power-law: got %f, want %f", got, 1/1.6)
+	}
+	// Linear forgetting reaches zero; exponential and power-law do not.
+	if activation(nil, 10) != 0 || activation(ExponentialDecay, 10) == 0 || activation(PowerLawDecay(1), 10) == 0 {
+		t.Error("unexpected long-run decay")
+	}
+}
+
+func TestSTM_DecayFuncEmotionalModulation(t *testing.T) {
+	for name, fn := range map[string]DecayFunc{
+		"linear": LinearDecay, "exponential": ExponentialDecay,
+		"power-law": PowerLawDecay(0.5), "act-r": ACTRDecay(0.5),
+	} {
+		config := DefaultSTMConfig()
+		config.DecayFunc = fn
+		stm := NewSTM(config)
+		stm.SetItems([]*WorkingMemoryItem{
+			{Topic: "emotional", Keywords: []string{"x"}, Activation: 1.0, Emotional: true},
+			{Topic: "normal", Keywords: []string{"y"}, Activation: 1.0},
+		})
+		stm.Update(3, "zzz", nil)
+		items := stm.Items()
+		if len(items) != 2 || items[0].Activation <= items[1].Activation {
+			t.Errorf("%s: emotional item should decay slower: %+v", name, items)
+		}
+	}
+}
+
+func TestSTM_ACTRRefreshCountsAsPresentation(t *testing.T) {
+	config := DefaultSTMConfig()
+	config.DecayFunc = ACTRDecay(0.5)
+	stm := NewSTM(config)
+	stm.SetItems([]*WorkingMemoryItem{
+		{Topic: "rehearsed", Keywords: []string{"京都"}, Activation: 1.0},
+		{Topic: "once", Keywords: []string{"締切"}, Activation: 1.0},
+	})
+	stm.Update(1, "京都", nil)
+	stm.Update(2, "京都", nil)
+	stm.Update(3, "zzz", nil)
+
+	items := stm.Items()
+	if items[0].Presentations != 3 || items[1].Presentations != 1 {
+		t.Fatalf("unexpected presentations: %d, %d", items[0].Presentations, items[1].Presentations)
+	}
+	if items[0].Activation <= items[1].Activation {
+		t.Errorf("rehearsed item should be more active: %f <= %f", items[0].Activation, items[1].Activation)
+	}
+}
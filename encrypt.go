@@ -0,0 +1,380 @@
+package memai
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownKey is returned when a KeyProvider has no key with the requested
+// ID.
+var ErrUnknownKey = errors.New("memai: unknown encryption key")
+
+// KeyProvider supplies AES keys (16, 24 or 32 bytes) for EncryptingStore.
+// Implement this interface to fetch keys from a KMS or secret manager. Key
+// IDs are recorded in every ciphertext, so a key's material must never change
+// under the same ID.
+type KeyProvider interface {
+	// CurrentKey returns the key new data is encrypted with.
+	CurrentKey(ctx context.Context) (id string, key []byte, err error)
+	// Key returns the key with the given ID, for decryption.
+	Key(ctx context.Context, id string) ([]byte, error)
+}
+
+// StaticKeyProvider is a KeyProvider over a fixed set of keys.
+type StaticKeyProvider struct {
+	current string
+	keys    map[string][]byte
+}
+
+// NewStaticKeyProvider returns a provider that encrypts with keys[current]
+// and can decrypt with any key in keys. To rotate, add a new key, make it
+// current and run EncryptingStore.Reencrypt before removing the old one.
+func NewStaticKeyProvider(current string, keys map[string][]byte) (*StaticKeyProvider, error) {
+	if _, ok := keys[current]; !ok {
+		return nil, fmt.Errorf("key %q: %w", current, ErrUnknownKey)
+	}
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("invalid key ID %q", id)
+		}
+		if _, err := aes.NewCipher(key); err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+	}
+	return &StaticKeyProvider{current: current, keys: keys}, nil
+}
+
+// CurrentKey implements KeyProvider.
+func (p *StaticKeyProvider) CurrentKey(_ context.Context) (string, []byte, error) {
+	return p.current, p.keys[p.current], nil
+}
+
+// Key implements KeyProvider.
+func (p *StaticKeyProvider) Key(_ context.Context, id string) ([]byte, error) {
+	key, ok := p.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("key %q: %w", id, ErrUnknownKey)
+	}
+	return key, nil
+}
+
+// EncryptionOptions configures an EncryptingStore.
+type EncryptionOptions struct {
+	// EncryptEmbeddings moves the embedding into the ciphertext as well. The
+	// backend then stores no vector (EmbeddingDim is kept), so it can no
+	// longer search by similarity itself.
+	EncryptEmbeddings bool
+}
+
+// envelopePrefix marks encrypted content. The full format is
+// "memai:enc:v1:<key ID>:<payload kind>:<base64 nonce+ciphertext>".
+const envelopePrefix = "memai:enc:v1:"
+
+// Payload kinds of an envelope.
+const (
+	payloadContent          = "c"  // Plaintext is the content
+	payloadContentEmbedding = "ce" // Plaintext is an encryptedPayload in JSON
+)
+
+type encryptedPayload struct {
+	Content   string    `json:"content"`
+	Embedding []float64 `json:"embedding,omitempty"`
+}
+
+// EncryptingStore is a MemoryStore decorator that encrypts memory content,
+// and optionally embeddings, with AES-GCM before it reaches the wrapped
+// store, and decrypts it in GetMemories. Other fields (ThreadKey, Entities,
+// dates) are stored as they are. Content that is not encrypted, such as
+// memories saved before the decorator was introduced, is returned as is.
+//
+// To combine with RedactingStore, put the redaction outside:
+// NewRedactingStore(NewEncryptingStore(store, ...), r).
+type EncryptingStore[ID comparable] struct {
+	storeDecorator[ID]
+	keys    KeyProvider
+	opts    EncryptionOptions
+	ciphers sync.Map // key ID -> cipher.AEAD
+}
+
+// NewEncryptingStore wraps store so that content is encrypted at rest with
+// keys from keys. Optional capabilities of store are passed through.
+func NewEncryptingStore[ID comparable](store MemoryStore[ID], keys KeyProvider, opts EncryptionOptions) *EncryptingStore[ID] {
+	return &EncryptingStore[ID]{storeDecorator: storeDecorator[ID]{inner: store}, keys: keys, opts: opts}
+}
+
+// GetMemories returns the memories of the wrapped store, decrypted.
+func (s *EncryptingStore[ID]) GetMemories(ctx context.Context) ([]Memory[ID], error) {
+	memories, err := s.inner.GetMemories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Memory[ID], len(memories))
+	for i, mem := range memories {
+		if err := s.decrypt(ctx, &mem); err != nil {
+			return nil, fmt.Errorf("decrypt memory %v: %w", mem.ID, err)
+		}
+		out[i] = mem
+	}
+	return out, nil
+}
+
+// SaveMemory encrypts a copy of mem and saves it. mem itself is left in
+// plaintext; an ID assigned by the wrapped store is copied back.
+func (s *EncryptingStore[ID]) SaveMemory(ctx context.Context, mem *Memory[ID]) error {
+	enc := *mem
+	if err := s.encrypt(ctx, &enc, mem.Embedding); err != nil {
+		return err
+	}
+	if err := s.inner.SaveMemory(ctx, &enc); err != nil {
+		return err
+	}
+	mem.ID = enc.ID
+	return nil
+}
+
+// UpdateContent encrypts content and forwards it to the wrapped store, which
+// must implement ContentUpdater. With EncryptEmbeddings the stored embedding
+// is kept.
+func (s *EncryptingStore[ID]) UpdateContent(ctx context.Context, id ID, content string) error {
+	updater, ok := capability[ContentUpdater[ID]](s.inner)
+	if !ok {
+		return ErrUnsupported
+	}
+	mem := Memory[ID]{ID: id, Content: content}
+	var embedding []float64
+	if s.opts.EncryptEmbeddings {
+		current, err := s.find(ctx, id)
+		if err != nil {
+			return err
+		}
+		embedding = current.Embedding
+	}
+	if err := s.encrypt(ctx, &mem, embedding); err != nil {
+		return err
+	}
+	return updater.UpdateContent(ctx, id, mem.Content)
+}
+
+// UpdateEmbedding replaces the embedding of a memory. With EncryptEmbeddings
+// it re-encrypts the content together with the new embedding, which needs
+// ContentUpdater as well as EmbeddingUpdater on the wrapped store.
+func (s *EncryptingStore[ID]) UpdateEmbedding(ctx context.Context, id ID, embedding []float64, model string) error {
+	if !s.opts.EncryptEmbeddings {
+		return s.storeDecorator.UpdateEmbedding(ctx, id, embedding, model)
+	}
+	embUpdater, ok := capability[EmbeddingUpdater[ID]](s.inner)
+	if !ok {
+		return ErrUnsupported
+	}
+	updater, ok := capability[ContentUpdater[ID]](s.inner)
+	if !ok {
+		return ErrUnsupported
+	}
+	current, err := s.find(ctx, id)
+	if err != nil {
+		return err
+	}
+	mem := Memory[ID]{ID: id, Content: current.Content}
+	if err := s.encrypt(ctx, &mem, embedding); err != nil {
+		return err
+	}
+	if err := updater.UpdateContent(ctx, id, mem.Content); err != nil {
+		return err
+	}
+	return embUpdater.UpdateEmbedding(ctx, id, nil, model)
+}
+
+// Reencrypt rewrites every memory not encrypted with the current key,
+// including plaintext ones, and returns how many it rewrote. Run it after
+// rotating keys or changing EncryptEmbeddings. The wrapped store must
+// implement ContentUpdater, and EmbeddingUpdater when embeddings move into or
+// out of the ciphertext; both are checked before anything is written. An
+// embedding is always stored in one place or the other, so Reencrypt can be
+// re-run after a failure.
+func (s *EncryptingStore[ID]) Reencrypt(ctx context.Context) (int, error) {
+	updater, ok := capability[ContentUpdater[ID]](s.inner)
+	if !ok {
+		return 0, fmt.Errorf("reencrypt: %w (ContentUpdater)", ErrUnsupported)
+	}
+	embUpdater, canUpdateEmbedding := capability[EmbeddingUpdater[ID]](s.inner)
+	currentID, _, err := s.keys.CurrentKey(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("key provider error: %w", err)
+	}
+	memories, err := s.inner.GetMemories(ctx)
+	if err != nil {
+		return 0, err
+	}
+	// Embeddings move into the ciphertext, or back out of it, when
+	// EncryptEmbeddings changed since the memory was written.
+	moves := func(mem Memory[ID]) (moveIn, moveOut bool) {
+		_, kind, _, _ := parseEnvelope(mem.Content)
+		return s.opts.EncryptEmbeddings && len(mem.Embedding) > 0,
+			!s.opts.EncryptEmbeddings && kind == payloadContentEmbedding
+	}
+	if !canUpdateEmbedding {
+		for _, mem := range memories {
+			if moveIn, moveOut := moves(mem); moveIn || moveOut {
+				return 0, fmt.Errorf("reencrypt: %w (EmbeddingUpdater)", ErrUnsupported)
+			}
+		}
+	}
+	done := 0
+	for _, mem := range memories {
+		if err := ctx.Err(); err != nil {
+			return done, err
+		}
+		keyID, _, _, encrypted := parseEnvelope(mem.Content)
+		moveIn, moveOut := moves(mem)
+		if encrypted && keyID == currentID && !moveIn && !moveOut {
+			continue
+		}
+		if err := s.decrypt(ctx, &mem); err != nil {
+			return done, fmt.Errorf("decrypt memory %v: %w", mem.ID, err)
+		}
+		plain := mem
+		if err := s.encrypt(ctx, &mem, plain.Embedding); err != nil {
+			return done, err
+		}
+		// Write the embedding's new home before removing the old one.
+		if moveOut {
+			if err := embUpdater.UpdateEmbedding(ctx, mem.ID, plain.Embedding, plain.EmbeddingModel); err != nil {
+				return done, err
+			}
+		}
+		if err := updater.UpdateContent(ctx, mem.ID, mem.Content); err != nil {
+			return done, err
+		}
+		if moveIn {
+			if err := embUpdater.UpdateEmbedding(ctx, mem.ID, nil, plain.EmbeddingModel); err != nil {
+				return done, err
+			}
+		}
+		done++
+	}
+	return done, nil
+}
+
+// find returns the decrypted memory with the given ID.
+func (s *EncryptingStore[ID]) find(ctx context.Context, id ID) (Memory[ID], error) {
+	memories, err := s.GetMemories(ctx)
+	if err != nil {
+		return Memory[ID]{}, err
+	}
+	for _, mem := range memories {
+		if mem.ID == id {
+			return mem, nil
+		}
+	}
+	return Memory[ID]{}, fmt.Errorf("memory %v not found", id)
+}
+
+// encrypt replaces mem.Content with an envelope. With EncryptEmbeddings,
+// embedding goes into the envelope and mem.Embedding is cleared.
+func (s *EncryptingStore[ID]) encrypt(ctx context.Context, mem *Memory[ID], embedding []float64) error {
+	keyID, key, err := s.keys.CurrentKey(ctx)
+	if err != nil {
+		return fmt.Errorf("key provider error: %w", err)
+	}
+	aead, err := s.cipher(keyID, key)
+	if err != nil {
+		return err
+	}
+	kind, plaintext := payloadContent, []byte(mem.Content)
+	if s.opts.EncryptEmbeddings && len(embedding) > 0 {
+		kind = payloadContentEmbedding
+		if plaintext, err = json.Marshal(encryptedPayload{Content: mem.Content, Embedding: embedding}); err != nil {
+			return err
+		}
+		mem.EmbeddingDim = len(embedding)
+		mem.Embedding = nil
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	header := envelopePrefix + keyID + ":" + kind + ":"
+	sealed := aead.Seal(nonce, nonce, plaintext, []byte(header))
+	mem.Content = header + base64.RawStdEncoding.EncodeToString(sealed)
+	return nil
+}
+
+// decrypt restores mem.Content (and mem.Embedding) from an envelope. Content
+// without an envelope is left untouched.
+func (s *EncryptingStore[ID]) decrypt(ctx context.Context, mem *Memory[ID]) error {
+	keyID, kind, data, ok := parseEnvelope(mem.Content)
+	if !ok {
+		return nil
+	}
+	key, err := s.keys.Key(ctx, keyID)
+	if err != nil {
+		return fmt.Errorf("key provider error: %w", err)
+	}
+	aead, err := s.cipher(keyID, key)
+	if err != nil {
+		return err
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(data)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return errors.New("malformed ciphertext")
+	}
+	header := envelopePrefix + keyID + ":" + kind + ":"
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(header))
+	if err != nil {
+		return err
+	}
+	switch kind {
+	case payloadContent:
+		mem.Content = string(plaintext)
+	case payloadContentEmbedding:
+		var p encryptedPayload
+		if err := json.Unmarshal(plaintext, &p); err != nil {
+			return err
+		}
+		mem.Content = p.Content
+		mem.Embedding = p.Embedding
+		mem.EmbeddingDim = len(p.Embedding)
+	default:
+		return fmt.Errorf("unknown payload kind %q", kind)
+	}
+	return nil
+}
+
+// cipher returns the AES-GCM instance for a key, cached by key ID.
+func (s *EncryptingStore[ID]) cipher(keyID string, key []byte) (cipher.AEAD, error) {
+	if aead, ok := s.ciphers.Load(keyID); ok {
+		return aead.(cipher.AEAD), nil
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("key %q: %w", keyID, err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	s.ciphers.Store(keyID, aead)
+	return aead, nil
+}
+
+// parseEnvelope splits encrypted content into its key ID, payload kind and
+// base64 data. ok is false for plaintext.
+func parseEnvelope(content string) (keyID, kind, data string, ok bool) {
+	rest, ok := strings.CutPrefix(content, envelopePrefix)
+	if !ok {
+		return "", "", "", false
+	}
+	parts := strings.SplitN(rest, ":", 3)
+	if len(parts) != 3 {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], parts[2], true
+}
@@ -0,0 +1,245 @@
+package memai
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// contentStore is a mockStore that implements ContentUpdater.
+type contentStore struct {
+	mockStore
+}
+
+func (s *contentStore) UpdateContent(_ context.Context, id int, content string) error {
+	for i := range s.memories {
+		if s.memories[i].ID == id {
+			s.memories[i].Content = content
+		}
+	}
+	return nil
+}
+
+func testKeys(t *testing.T, current string) *StaticKeyProvider {
+	t.Helper()
+	keys, err := NewStaticKeyProvider(current, map[string][]byte{
+		"k1": bytes.Repeat([]byte{1}, 32),
+		"k2": bytes.Repeat([]byte{2}, 32),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return keys
+}
+
+func TestEncryptingStore_RoundTripThroughLTM(t *testing.T) {
+	inner := &contentStore{}
+	store := NewEncryptingStore[int](inner, testKeys(t, "k1"), EncryptionOptions{})
+	ltm := NewLTM[int](store, nil, DefaultLTMConfig())
+	ctx := context.Background()
+
+	mem := &Memory[int]{ID: 1, Content: "秘密の話", Embedding: []float64{1, 0, 0}}
+	if err := ltm.Save(ctx, mem); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mem.Content != "秘密の話" {
+		t.Errorf("caller's memory should stay in plaintext, got %q", mem.Content)
+	}
+	stored := inner.memories[0].Content
+	if !strings.HasPrefix(stored, "memai:enc:v1:k1:c:") || strings.Contains(stored, "秘密") {
+		t.Errorf("content should be encrypted at rest, got %q", stored)
+	}
+
+	results, err := ltm.Search(ctx, SearchQuery{QueryEmbedding: []float64{1, 0, 0}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].Memory.Content != "秘密の話" {
+		t.Errorf("expected decrypted result, got %+v", results)
+	}
+	if inner.memories[0].Content != stored {
+		t.Error("GetMemories must not modify the wrapped store's data")
+	}
+}
+
+func TestEncryptingStore_EncryptEmbeddings(t *testing.T) {
+	inner := &contentStore{}
+	store := NewEncryptingStore[int](inner, testKeys(t, "k1"), EncryptionOptions{EncryptEmbeddings: true})
+	ctx := context.Background()
+
+	if err := store.SaveMemory(ctx, &Memory[int]{ID: 1, Content: "x", Embedding: []float64{0.6, 0.8}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if inner.memories[0].Embedding != nil || inner.memories[0].EmbeddingDim != 2 {
+		t.Errorf("embedding should be moved into the ciphertext: %+v", inner.memories[0])
+	}
+	if err := store.UpdateEmbedding(ctx, 1, []float64{0, 1, 0}, "m2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := store.GetMemories(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got[0].Content != "x" || len(got[0].Embedding) != 3 || got[0].EmbeddingDim != 3 || got[0].EmbeddingModel != "m2" {
+		t.Errorf("unexpected decrypted memory: %+v", got[0])
+	}
+}
+
+// flakyEmbeddingStore is a contentStore whose UpdateEmbedding fails while
+// fail is set.
+type flakyEmbeddingStore struct {
+	contentStore
+	fail bool
+}
+
+func (s *flakyEmbeddingStore) UpdateEmbedding(ctx context.Context, id int, emb []float64, model string) error {
+	if s.fail {
+		return errors.New("write failed")
+	}
+	return s.contentStore.UpdateEmbedding(ctx, id, emb, model)
+}
+
+func TestEncryptingStore_ReencryptKeepsEmbeddingOnFailure(t *testing.T) {
+	inner := &flakyEmbeddingStore{}
+	ctx := context.Background()
+	sealed := NewEncryptingStore[int](inner, testKeys(t, "k1"), EncryptionOptions{EncryptEmbeddings: true})
+	if err := sealed.SaveMemory(ctx, &Memory[int]{ID: 1, Content: "x", Embedding: []float64{0.6, 0.8}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	inner.fail = true
+	unsealed := NewEncryptingStore[int](inner, testKeys(t, "k1"), EncryptionOptions{})
+	if _, err := unsealed.Reencrypt(ctx); err == nil {
+		t.Fatal("expected error from UpdateEmbedding")
+	}
+	got, err := unsealed.GetMemories(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got[0].Embedding) != 2 {
+		t.Fatalf("embedding lost after failed reencrypt: %+v", got[0])
+	}
+
+	inner.fail = false
+	if n, err := unsealed.Reencrypt(ctx); err != nil || n != 1 {
+		t.Fatalf("re-run: n=%d err=%v", n, err)
+	}
+	if !strings.HasPrefix(inner.memories[0].Content, "memai:enc:v1:k1:c:") || len(inner.memories[0].Embedding) != 2 {
+		t.Errorf("embedding should be moved out of the ciphertext: %+v", inner.memories[0])
+	}
+}
+
+// contentOnlyStore implements ContentUpdater but not EmbeddingUpdater.
+type contentOnlyStore struct {
+	memories []Memory[int]
+}
+
+func (s *contentOnlyStore) GetMemories(context.Context) ([]Memory[int], error) {
+	return s.memories, nil
+}
+func (s *contentOnlyStore) SaveMemory(_ context.Context, mem *Memory[int]) error {
+	s.memories = append(s.memories, *mem)
+	return nil
+}
+func (s *contentOnlyStore) DeleteMemory(context.Context, int) error         { return nil }
+func (s *contentOnlyStore) UpdateBoost(context.Context, int, float64) error { return nil }
+func (s *contentOnlyStore) UpdateContent(_ context.Context, id int, content string) error {
+	for i := range s.memories {
+		if s.memories[i].ID == id {
+			s.memories[i].Content = content
+		}
+	}
+	return nil
+}
+
+func TestEncryptingStore_ReencryptChecksEmbeddingUpdater(t *testing.T) {
+	inner := &contentOnlyStore{}
+	inner.memories = []Memory[int]{{ID: 1, Content: "x", Embedding: []float64{1, 0}}}
+	store := NewEncryptingStore[int](inner, testKeys(t, "k1"), EncryptionOptions{EncryptEmbeddings: true})
+	if _, err := store.Reencrypt(context.Background()); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	if inner.memories[0].Content != "x" {
+		t.Errorf("nothing should be written before the capability check, got %q", inner.memories[0].Content)
+	}
+}
+
+func TestEncryptingStore_CapabilitiesSeeThroughDecorators(t *testing.T) {
+	r, _ := NewRedactor(RedactorConfig{})
+	inner := &contentOnlyStore{}
+	inner.memories = []Memory[int]{{ID: 1, Content: "x", Embedding: []float64{1, 0}}}
+	store := NewEncryptingStore[int](NewRedactingStore[int](inner, r), testKeys(t, "k1"), EncryptionOptions{EncryptEmbeddings: true})
+	if _, err := store.Reencrypt(context.Background()); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	if err := store.UpdateEmbedding(context.Background(), 1, []float64{0, 1}, "m"); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	if inner.memories[0].Content != "x" {
+		t.Errorf("nothing should be written before the capability check, got %q", inner.memories[0].Content)
+	}
+}
+
+func TestEncryptingStore_KeyRotation(t *testing.T) {
+	inner := &contentStore{}
+	ctx := context.Background()
+	old := NewEncryptingStore[int](inner, testKeys(t, "k1"), EncryptionOptions{})
+	if err := old.SaveMemory(ctx, &Memory[int]{ID: 1, Content: "one"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	inner.memories = append(inner.memories, Memory[int]{ID: 2, Content: "legacy plaintext"})
+
+	rotated := NewEncryptingStore[int](inner, testKeys(t, "k2"), EncryptionOptions{})
+	n, err := rotated.Reencrypt(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 memories rewritten, got %d", n)
+	}
+	for _, mem := range inner.memories {
+		if !strings.HasPrefix(mem.Content, "memai:enc:v1:k2:") {
+			t.Errorf("memory %d not under the new key: %q", mem.ID, mem.Content)
+		}
+	}
+	if n, _ := rotated.Reencrypt(ctx); n != 0 {
+		t.Errorf("second run should be a no-op, rewrote %d", n)
+	}
+
+	onlyNew, _ := NewStaticKeyProvider("k2", map[string][]byte{"k2": bytes.Repeat([]byte{2}, 32)})
+	got, err := NewEncryptingStore[int](inner, onlyNew, EncryptionOptions{}).GetMemories(ctx)
+	if err != nil {
+		t.Fatalf("old key should no longer be needed: %v", err)
+	}
+	if got[0].Content != "one" || got[1].Content != "legacy plaintext" {
+		t.Errorf("unexpected contents: %q, %q", got[0].Content, got[1].Content)
+	}
+}
+
+func TestEncryptingStore_Errors(t *testing.T) {
+	ctx := context.Background()
+	inner := &contentStore{}
+	store := NewEncryptingStore[int](inner, testKeys(t, "k1"), EncryptionOptions{})
+	if err := store.SaveMemory(ctx, &Memory[int]{ID: 1, Content: "x"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c := inner.memories[0].Content
+	inner.memories[0].Content = c[:len(c)-2] + "AA"
+	if _, err := store.GetMemories(ctx); err == nil {
+		t.Error("expected error for tampered ciphertext")
+	}
+
+	onlyK2, _ := NewStaticKeyProvider("k2", map[string][]byte{"k2": bytes.Repeat([]byte{2}, 32)})
+	inner.memories[0].Content = c
+	if _, err := NewEncryptingStore[int](inner, onlyK2, EncryptionOptions{}).GetMemories(ctx); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("expected ErrUnknownKey, got %v", err)
+	}
+
+	plain := NewEncryptingStore[int](&mockStore{}, testKeys(t, "k1"), EncryptionOptions{})
+	if _, err := plain.Reencrypt(ctx); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,276 @@
+package memai
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// EntityExtractor finds the names of people, places and projects in text.
+// Implement this interface to plug in an NER model or an LLM.
+type EntityExtractor interface {
+	ExtractEntities(text string) []string
+}
+
+// maxEntityRunes bounds the length of an extracted entity; longer quoted
+// spans are sentences, not names.
+const maxEntityRunes = 30
+
+// honorificsJA are Japanese name suffixes. The name is the run of kanji,
+// katakana or Latin letters immediately before the suffix.
+var honorificsJA = []string{"さん", "様", "さま", "くん", "君", "ちゃん", "先生", "氏", "殿"}
+
+// honorificStopwordsJA are words that take honorifics without being names
+// (皆さん, お客様, お母さん, ...).
+var honorificStopwordsJA = map[string]bool{
+	"皆": true, "客": true, "神": true, "母": true, "父": true,
+	"兄": true, "姉": true, "奥": true, "子": true, "嬢": true,
+}
+
+// quotePairs maps opening quotes to their closing counterparts.
+var quotePairs = map[rune]rune{'「': '」', '『': '』', '“': '”', '"': '"'}
+
+// capitalStopwordsEN are capitalised words that are not entities on their
+// own: sentence starters, pronouns, days and months. They are also stripped
+// from the front of multi-word spans ("The Berlin trip" -> "Berlin").
+var capitalStopwordsEN = map[string]bool{
+	"i": true, "i'm": true, "i've": true, "i'll": true, "i'd": true,
+	"a": true, "an": true, "the": true, "this": true, "that": true, "these": true, "those": true,
+	"my": true, "our": true, "your": true, "his": true, "her": true, "their": true, "its": true,
+	"we": true, "you": true, "he": true, "she": true, "it": true, "they": true,
+	"hi": true, "hello": true, "hey": true, "yes": true, "no": true, "ok": true, "okay": true,
+	"thanks": true, "thank": true, "please": true, "sorry": true, "remember": true,
+	"can": true, "could": true, "would": true, "should": true, "will": true, "do": true, "does": true,
+	"did": true, "is": true, "are": true, "was": true, "were": true, "let's": true, "let": true,
+	"what": true, "when": true, "where": true, "why": true, "how": true, "who": true, "which": true,
+	"so": true, "but": true, "and": true, "or": true, "also": true, "then": true, "if": true,
+	"today": true, "tomorrow": true, "yesterday": true, "don't": true,
+	"monday": true, "tuesday": true, "wednesday": true, "thursday": true, "friday": true,
+	"saturday": true, "sunday": true, "january": true, "february": true, "march": true,
+	"april": true, "may": true, "june": true, "july": true, "august": true, "september": true,
+	"october": true, "november": true, "december": true,
+}
+
+// HeuristicEntityExtractor implements EntityExtractor with rules for
+// Japanese and English text, no model required:
+//
+//   - names followed by an honorific (田中さん, 山田様 -> 田中, 山田)
+//   - quoted names (「プロジェクトX」, "Blue Ocean")
+//   - katakana runs (ベルリン, スズキ)
+//   - capitalised English spans (the Berlin trip -> Berlin, New York)
+//
+// It favours recall over precision; loanwords written in katakana are
+// reported too.
+type HeuristicEntityExtractor struct{}
+
+// NewHeuristicEntityExtractor returns the built-in rule-based extractor.
+func NewHeuristicEntityExtractor() *HeuristicEntityExtractor {
+	return &HeuristicEntityExtractor{}
+}
+
+// ExtractEntities implements EntityExtractor. Entities are returned in order
+// of first appearance, without duplicates (compared case-insensitively).
+func (e *HeuristicEntityExtractor) ExtractEntities(text string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	add := func(s string) {
+		s = strings.TrimSpace(s)
+		if s == "" || utf8.RuneCountInString(s) > maxEntityRunes {
+			return
+		}
+		k := entityKey(s)
+		if seen[k] {
+			return
+		}
+		seen[k] = true
+		out = append(out, s)
+	}
+
+	runes := []rune(text)
+	quotedEntities(runes, add)
+	honorificEntities(runes, add)
+	katakanaEntities(runes, add)
+	capitalisedEntities(runes, add)
+	return out
+}
+
+// quotedEntities reports spans enclosed in a pair of quotes.
+func quotedEntities(runes []rune, add func(string)) {
+	for i := 0; i < len(runes); i++ {
+		closing, ok := quotePairs[runes[i]]
+		if !ok {
+			continue
+		}
+		for j := i + 1; j < len(runes); j++ {
+			if runes[j] == '\n' {
+				break
+			}
+			if runes[j] == closing {
+				add(string(runes[i+1 : j]))
+				i = j
+				break
+			}
+		}
+	}
+}
+
+// honorificEntities reports the name preceding each Japanese honorific.
+func honorificEntities(runes []rune, add func(string)) {
+	for i := range runes {
+		for _, h := range honorificsJA {
+			if !hasRunePrefix(runes[i:], h) {
+				continue
+			}
+			start := i
+			for start > 0 && isNameRune(runes[start-1]) {
+				start--
+			}
+			name := string(runes[start:i])
+			if name != "" && !honorificStopwordsJA[name] {
+				add(name)
+			}
+			break
+		}
+	}
+}
+
+// katakanaEntities reports runs of two or more katakana.
+func katakanaEntities(runes []rune, add func(string)) {
+	for i := 0; i < len(runes); {
+		if !isKatakana(runes[i]) || runes[i] == 'ー' || runes[i] == '・' {
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && isKatakana(runes[j]) {
+			j++
+		}
+		if j-i >= 2 {
+			add(strings.TrimRight(string(runes[i:j]), "・"))
+		}
+		i = j
+	}
+}
+
+// capitalisedEntities reports runs of capitalised Latin words separated only
+// by spaces, with leading stopwords removed. A possessive ends the span.
+func capitalisedEntities(runes []rune, add func(string)) {
+	var span []string
+	flush := func() {
+		for len(span) > 0 && capitalStopwordsEN[strings.ToLower(span[0])] {
+			span = span[1:]
+		}
+		// A lone capital letter ("X", "B") is an initial, not a name.
+		if len(span) > 1 || (len(span) == 1 && utf8.RuneCountInString(span[0]) > 1) {
+			add(strings.Join(span, " "))
+		}
+		span = span[:0]
+	}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case isLatinLetter(r):
+			j := i
+			for j < len(runes) && (isLatinLetter(runes[j]) || unicode.IsDigit(runes[j]) ||
+				((runes[j] == '\'' || runes[j] == '’' || runes[j] == '-') && j+1 < len(runes) && isLatinLetter(runes[j+1]))) {
+				j++
+			}
+			word := string(runes[i:j])
+			i = j
+			possessive := false
+			for _, suf := range []string{"'s", "’s"} {
+				if w, ok := strings.CutSuffix(word, suf); ok {
+					word, possessive = w, true
+				}
+			}
+			if unicode.IsUpper([]rune(word)[0]) {
+				span = append(span, word)
+			} else {
+				flush()
+			}
+			if possessive {
+				flush()
+			}
+		case r == ' ':
+			i++
+		default:
+			flush()
+			i++
+		}
+	}
+	flush()
+}
+
+// entityKey normalizes an entity for comparison.
+func entityKey(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// entityKeys returns the set of normalized entities, or nil if there are none.
+func entityKeys(entities []string) map[string]struct{} {
+	if len(entities) == 0 {
+		return nil
+	}
+	set := make(map[string]struct{}, len(entities))
+	for _, e := range entities {
+		set[entityKey(e)] = struct{}{}
+	}
+	return set
+}
+
+// countShared returns how many of entities appear in set.
+func countShared(set map[string]struct{}, entities []string) int {
+	n := 0
+	for _, e := range entities {
+		if _, ok := set[entityKey(e)]; ok {
+			n++
+		}
+	}
+	return n
+}
+
+// queryEntities returns the entities of q, extracting them from the query
+// text when none were given.
+func (l *LTM[ID]) queryEntities(q SearchQuery) []string {
+	if len(q.Entities) > 0 || l.config.EntityExtractor == nil || q.Query == "" {
+		return q.Entities
+	}
+	return l.config.EntityExtractor.ExtractEntities(q.Query)
+}
+
+// sharedEntities returns how many query entities a memory mentions.
+func (sc *scoring) sharedEntities(entities []string) int {
+	if len(sc.entities) == 0 {
+		return 0
+	}
+	return countShared(sc.entities, entities)
+}
+
+// admits reports whether a memory passes the entity filter of the query.
+func (sc *scoring) admits(entities []string) bool {
+	return !sc.query.RequireEntity || len(sc.entities) == 0 || sc.sharedEntities(entities) > 0
+}
+
+func hasRunePrefix(runes []rune, prefix string) bool {
+	for _, p := range prefix {
+		if len(runes) == 0 || runes[0] != p {
+			return false
+		}
+		runes = runes[1:]
+	}
+	return true
+}
+
+func isKatakana(r rune) bool {
+	return unicode.Is(unicode.Katakana, r) || r == 'ー' || r == '・'
+}
+
+func isLatinLetter(r rune) bool {
+	return r < unicode.MaxLatin1 && unicode.IsLetter(r)
+}
+
+// isNameRune reports whether r can be part of a name before an honorific.
+func isNameRune(r rune) bool {
+	return unicode.Is(unicode.Han, r) || isKatakana(r) || isLatinLetter(r) || r == '々'
+}
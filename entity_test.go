@@ -0,0 +1,70 @@
+package memai
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestHeuristicEntityExtractor(t *testing.T) {
+	e := NewHeuristicEntityExtractor()
+	cases := []struct {
+		text string
+		want []string
+	}{
+		{"田中さんと山田様に会った", []string{"田中", "山田"}},
+		{"皆さん、お客様です", nil},
+		{"「プロジェクトX」の件", []string{"プロジェクトX", "プロジェクト"}},
+		{"ベルリンに行った", []string{"ベルリン"}},
+		{"We planned the Berlin trip with Anna.", []string{"Berlin", "Anna"}},
+		{"I met John Smith in New York on Monday", []string{"John Smith", "New York"}},
+		{"Tanaka's birthday", []string{"Tanaka"}},
+		{`He said "Blue Ocean" twice`, []string{"Blue Ocean"}},
+	}
+	for _, c := range cases {
+		if got := e.ExtractEntities(c.text); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("ExtractEntities(%q) = %q, want %q", c.text, got, c.want)
+		}
+	}
+}
+
+func TestLTM_EntityBoostAndFilter(t *testing.T) {
+	store := &mockStore{
+		memories: []Memory[int]{
+			{ID: 1, Content: "other", Embedding: []float64{1, 0.1}, Entities: []string{"佐藤"}},
+			{ID: 2, Content: "tanaka", Embedding: []float64{1, 0.2}, Entities: []string{"田中"}},
+		},
+	}
+	cfg := DefaultLTMConfig()
+	cfg.EntityExtractor = NewHeuristicEntityExtractor()
+	ltm := NewLTM(store, nil, cfg)
+	ctx := context.Background()
+
+	results, err := ltm.Search(ctx, SearchQuery{Query: "田中さんの話", QueryEmbedding: []float64{1, 0}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 2 || results[0].Memory.ID != 2 {
+		t.Fatalf("entity overlap should rank the tanaka memory first, got %+v", results)
+	}
+
+	results, err = ltm.Search(ctx, SearchQuery{QueryEmbedding: []float64{1, 0}, Entities: []string{"田中"}, RequireEntity: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].Memory.ID != 2 {
+		t.Errorf("RequireEntity should filter to the tanaka memory, got %+v", results)
+	}
+}
+
+func TestLTM_SaveExtractsEntities(t *testing.T) {
+	store := &mockStore{}
+	cfg := DefaultLTMConfig()
+	cfg.EntityExtractor = NewHeuristicEntityExtractor()
+	if err := NewLTM(store, nil, cfg).Save(context.Background(), &Memory[int]{ID: 1, Content: "鈴木さんとベルリンへ", Embedding: []float64{1}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := store.memories[0].Entities; !reflect.DeepEqual(got, []string{"鈴木", "ベルリン"}) {
+		t.Errorf("unexpected entities %q", got)
+	}
+}
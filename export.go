@@ -0,0 +1,331 @@
+package memai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ExportVersion is the schema version written by Export. Import reads this
+// version and older ones.
+const ExportVersion = 1
+
+// exportFormat identifies memai exports in the header.
+const exportFormat = "memai"
+
+var (
+	// ErrImportFormat is returned by Import for input that is not a memai
+	// export, or is from a newer schema version.
+	ErrImportFormat = errors.New("memai: unsupported import format")
+	// ErrImportConflict is returned by Import under ConflictError when a
+	// memory ID already exists in the store.
+	ErrImportConflict = errors.New("memai: memory already exists")
+)
+
+// ExportHeader is the first line of an export.
+type ExportHeader struct {
+	Format         string    `json:"format"` // Always "memai"
+	Version        int       `json:"version"`
+	EmbeddingModel string    `json:"embedding_model,omitempty"` // Shared model of all embeddings; "" if unknown or mixed
+	EmbeddingDim   int       `json:"embedding_dim,omitempty"`   // Shared dimension of all embeddings; 0 if mixed
+	Count          int       `json:"count"`                     // Number of memory lines that follow
+	ExportedAt     time.Time `json:"exported_at"`
+}
+
+// exportRecord is one memory line of an export. Field names are stable
+// across versions; new fields are only ever added.
+type exportRecord struct {
+	ID                 json.RawMessage `json:"id"`
+	Content            string          `json:"content"`
+	Embedding          []float64       `json:"embedding,omitempty"`
+	EmbeddingModel     string          `json:"embedding_model,omitempty"`
+	EmbeddingDim       int             `json:"embedding_dim,omitempty"`
+	ThreadKey          string          `json:"thread_key,omitempty"`
+	EventDate          string          `json:"event_date,omitempty"`
+	Boost              float64         `json:"boost,omitempty"`
+	EmotionalIntensity float64         `json:"emotional_intensity,omitempty"`
+	Namespace          *exportNS       `json:"namespace,omitempty"`
+	LastAccessed       *time.Time      `json:"last_accessed,omitempty"`
+	AccessCount        int             `json:"access_count,omitempty"`
+	Entities           []string        `json:"entities,omitempty"`
+	Kind               MemoryKind      `json:"kind,omitempty"`
+	CreatedAt          *time.Time      `json:"created_at,omitempty"`
+	Importance         float64         `json:"importance,omitempty"`
+	Provenance         *exportSource   `json:"provenance,omitempty"`
+	DeletedAt          *time.Time      `json:"deleted_at,omitempty"`
+}
+
+type exportNS struct {
+	Tenant string `json:"tenant,omitempty"`
+	User   string `json:"user,omitempty"`
+	Agent  string `json:"agent,omitempty"`
+}
+
+type exportSource struct {
+	Source         SourceType `json:"source,omitempty"`
+	MessageID      string     `json:"message_id,omitempty"`
+	Turn           int        `json:"turn,omitempty"`
+	ConversationID string     `json:"conversation_id,omitempty"`
+	SourceTime     *time.Time `json:"source_time,omitempty"`
+	Extractor      string     `json:"extractor,omitempty"`
+}
+
+// Export writes every memory of store to w as JSON Lines: an ExportHeader
+// line followed by one line per memory. When ctx carries a namespace (see
+// WithNamespace), only that namespace's memories are written, even if the
+// store ignores namespaces. Soft-deleted memories are included with their
+// deletion time. IDs are written with encoding/json, so ID must marshal to a
+// JSON value.
+func Export[ID comparable](ctx context.Context, store MemoryStore[ID], w io.Writer) error {
+	memories, err := store.GetMemories(ctx)
+	if err != nil {
+		return fmt.Errorf("memory store error: %w", err)
+	}
+	ns, _ := NamespaceFromContext(ctx)
+	memories = scopeMemories(memories, ns, true)
+	header := ExportHeader{Format: exportFormat, Version: ExportVersion, Count: len(memories), ExportedAt: time.Now().UTC()}
+	for i, mem := range memories {
+		if i == 0 {
+			header.EmbeddingModel, header.EmbeddingDim = mem.EmbeddingModel, len(mem.Embedding)
+			continue
+		}
+		if mem.EmbeddingModel != header.EmbeddingModel {
+			header.EmbeddingModel = ""
+		}
+		if len(mem.Embedding) != header.EmbeddingDim {
+			header.EmbeddingDim = 0
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(header); err != nil {
+		return err
+	}
+	for _, mem := range memories {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rec, err := toExportRecord(mem)
+		if err != nil {
+			return fmt.Errorf("export memory %v: %w", mem.ID, err)
+		}
+		if err := enc.Encode(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConflictPolicy selects what Import does with a memory whose ID already
+// exists in the store.
+type ConflictPolicy string
+
+const (
+	ConflictError     ConflictPolicy = "error"     // Stop with ErrImportConflict
+	ConflictSkip      ConflictPolicy = "skip"      // Keep the existing memory
+	ConflictOverwrite ConflictPolicy = "overwrite" // Delete the existing memory and import (see Import)
+)
+
+// ImportOptions configures Import.
+type ImportOptions[ID comparable] struct {
+	// RemapID maps an exported ID, in its JSON form (numbers as digits,
+	// strings unquoted), to the ID to save under. nil decodes the JSON into
+	// ID unchanged. Use it to move between ID types or avoid collisions.
+	RemapID func(old string) (ID, error)
+	// Conflict applies to IDs already in the store (default: ConflictError).
+	Conflict ConflictPolicy
+	// DropEmbeddings imports memories without embeddings, to be re-embedded
+	// by LTM.Reembed or on the next save.
+	DropEmbeddings bool
+}
+
+// ImportStats summarises an Import.
+type ImportStats struct {
+	Header      ExportHeader
+	Imported    int // Memories saved, including overwrites
+	Overwritten int // Existing memories replaced under ConflictOverwrite
+	Skipped     int // Existing memories kept under ConflictSkip
+}
+
+// Import reads an export produced by Export from r and saves its memories to
+// store, one at a time. It checks the header and that the number of memory
+// lines matches it, so a truncated file is reported. Memories saved before
+// an error stay saved; re-running with ConflictSkip resumes.
+//
+// When ctx carries a namespace, every memory is imported into it, whatever
+// namespace the export recorded, and an ID held by a memory of another
+// namespace is rejected with ErrNamespaceMismatch under every
+// ConflictPolicy.
+//
+// ConflictOverwrite is not atomic: the existing memory is deleted before its
+// replacement is saved, so if the save fails the memory is missing until
+// Import is re-run with ConflictOverwrite.
+func Import[ID comparable](ctx context.Context, r io.Reader, store MemoryStore[ID], opts ImportOptions[ID]) (ImportStats, error) {
+	if opts.Conflict == "" {
+		opts.Conflict = ConflictError
+	}
+	var stats ImportStats
+	dec := json.NewDecoder(r)
+	if err := dec.Decode(&stats.Header); err != nil {
+		return stats, fmt.Errorf("%w: reading header: %v", ErrImportFormat, err)
+	}
+	if stats.Header.Format != exportFormat || stats.Header.Version < 1 || stats.Header.Version > ExportVersion {
+		return stats, fmt.Errorf("%w: format %q version %d", ErrImportFormat, stats.Header.Format, stats.Header.Version)
+	}
+
+	ns, scoped := NamespaceFromContext(ctx)
+	existing, err := store.GetMemories(ctx)
+	if err != nil {
+		return stats, fmt.Errorf("memory store error: %w", err)
+	}
+	owners := make(map[ID]Namespace, len(existing))
+	for _, mem := range existing {
+		owners[mem.ID] = mem.Namespace
+	}
+
+	n := 0
+	for ; ; n++ {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+		var rec exportRecord
+		if err := dec.Decode(&rec); err == io.EOF {
+			break
+		} else if err != nil {
+			return stats, fmt.Errorf("%w: line %d: %v", ErrImportFormat, n+2, err)
+		}
+		mem, err := fromExportRecord(rec, opts)
+		if err != nil {
+			return stats, fmt.Errorf("%w: line %d: %v", ErrImportFormat, n+2, err)
+		}
+		if scoped {
+			mem.Namespace = ns
+		}
+		if owner, ok := owners[mem.ID]; ok {
+			if scoped && owner != ns {
+				return stats, fmt.Errorf("%w: memory %v is not in %s", ErrNamespaceMismatch, mem.ID, ns)
+			}
+			switch opts.Conflict {
+			case ConflictSkip:
+				stats.Skipped++
+				continue
+			case ConflictOverwrite:
+				if err := store.DeleteMemory(ctx, mem.ID); err != nil {
+					return stats, fmt.Errorf("memory store error: %w", err)
+				}
+				stats.Overwritten++
+			default:
+				return stats, fmt.Errorf("%w: %v", ErrImportConflict, mem.ID)
+			}
+		}
+		if err := store.SaveMemory(ctx, &mem); err != nil {
+			return stats, fmt.Errorf("memory store error: %w", err)
+		}
+		owners[mem.ID] = mem.Namespace
+		stats.Imported++
+	}
+	if n != stats.Header.Count {
+		return stats, fmt.Errorf("%w: header announces %d memories, found %d", ErrImportFormat, stats.Header.Count, n)
+	}
+	return stats, nil
+}
+
+func toExportRecord[ID comparable](mem Memory[ID]) (exportRecord, error) {
+	id, err := json.Marshal(mem.ID)
+	if err != nil {
+		return exportRecord{}, err
+	}
+	rec := exportRecord{
+		ID:                 id,
+		Content:            mem.Content,
+		Embedding:          mem.Embedding,
+		EmbeddingModel:     mem.EmbeddingModel,
+		EmbeddingDim:       mem.EmbeddingDim,
+		ThreadKey:          mem.ThreadKey,
+		EventDate:          mem.EventDate,
+		Boost:              mem.Boost,
+		EmotionalIntensity: mem.EmotionalIntensity,
+		LastAccessed:       timePtr(mem.LastAccessed),
+		AccessCount:        mem.AccessCount,
+		Entities:           mem.Entities,
+		Kind:               mem.Kind,
+		CreatedAt:          timePtr(mem.CreatedAt),
+		Importance:         mem.Importance,
+		DeletedAt:          timePtr(mem.DeletedAt),
+	}
+	if ns := mem.Namespace; !ns.IsZero() {
+		rec.Namespace = &exportNS{Tenant: ns.Tenant, User: ns.User, Agent: ns.Agent}
+	}
+	if p := mem.Provenance; p != (Provenance{}) {
+		rec.Provenance = &exportSource{
+			Source: p.Source, MessageID: p.MessageID, Turn: p.Turn,
+			ConversationID: p.ConversationID, SourceTime: timePtr(p.SourceTime), Extractor: p.Extractor,
+		}
+	}
+	return rec, nil
+}
+
+func fromExportRecord[ID comparable](rec exportRecord, opts ImportOptions[ID]) (Memory[ID], error) {
+	var mem Memory[ID]
+	if opts.RemapID != nil {
+		old := string(rec.ID)
+		if strings.HasPrefix(old, `"`) {
+			if err := json.Unmarshal(rec.ID, &old); err != nil {
+				return mem, fmt.Errorf("ID %s: %w", rec.ID, err)
+			}
+		}
+		id, err := opts.RemapID(old)
+		if err != nil {
+			return mem, fmt.Errorf("remap ID %s: %w", rec.ID, err)
+		}
+		mem.ID = id
+	} else if err := json.Unmarshal(rec.ID, &mem.ID); err != nil {
+		return mem, fmt.Errorf("ID %s: %w", rec.ID, err)
+	}
+	mem.Content = rec.Content
+	mem.ThreadKey = rec.ThreadKey
+	mem.EventDate = rec.EventDate
+	mem.Boost = rec.Boost
+	mem.EmotionalIntensity = rec.EmotionalIntensity
+	mem.AccessCount = rec.AccessCount
+	mem.Entities = rec.Entities
+	mem.Kind = rec.Kind
+	mem.Importance = rec.Importance
+	mem.LastAccessed = timeValue(rec.LastAccessed)
+	mem.CreatedAt = timeValue(rec.CreatedAt)
+	mem.DeletedAt = timeValue(rec.DeletedAt)
+	if !opts.DropEmbeddings {
+		mem.Embedding = rec.Embedding
+		mem.EmbeddingModel = rec.EmbeddingModel
+		mem.EmbeddingDim = rec.EmbeddingDim
+	}
+	if ns := rec.Namespace; ns != nil {
+		mem.Namespace = Namespace{Tenant: ns.Tenant, User: ns.User, Agent: ns.Agent}
+	}
+	if p := rec.Provenance; p != nil {
+		mem.Provenance = Provenance{
+			Source: p.Source, MessageID: p.MessageID, Turn: p.Turn,
+			ConversationID: p.ConversationID, SourceTime: timeValue(p.SourceTime), Extractor: p.Extractor,
+		}
+	}
+	return mem, nil
+}
+
+// timePtr returns nil for the zero time, so it is omitted from JSON.
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func timeValue(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
@@ -0,0 +1,158 @@
+package memai
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func exportFixture() *mockStore {
+	created := time.Date(2026, 6, 17, 9, 0, 0, 0, time.UTC)
+	return &mockStore{memories: []Memory[int]{
+		{
+			ID: 1, Content: "京都旅行", Embedding: []float64{1, 0}, EmbeddingModel: "m1", EmbeddingDim: 2,
+			ThreadKey: "trip", Boost: 0.1, Namespace: Namespace{User: "alice"}, Entities: []string{"京都"},
+			Kind: KindEpisodic, CreatedAt: created,
+			Provenance: Provenance{Source: SourceUserMessage, MessageID: "m-1", SourceTime: created},
+		},
+		{ID: 2, Content: "エビアレルギー", Embedding: []float64{0, 1}, EmbeddingModel: "m1", EmbeddingDim: 2, Kind: KindSemantic},
+	}}
+}
+
+func TestExportImport_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	src := exportFixture()
+	var buf bytes.Buffer
+	if err := Export[int](ctx, src, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], `"embedding_model":"m1"`) || !strings.Contains(lines[0], `"embedding_dim":2`) {
+		t.Fatalf("unexpected export:\n%s", buf.String())
+	}
+
+	dst := &mockStore{}
+	stats, err := Import[int](ctx, &buf, dst, ImportOptions[int]{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Imported != 2 || stats.Header.Version != ExportVersion {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	for i, want := range src.memories {
+		got := dst.memories[i]
+		if got.ID != want.ID || got.Content != want.Content || got.Namespace != want.Namespace ||
+			got.Provenance != want.Provenance || !got.CreatedAt.Equal(want.CreatedAt) ||
+			got.Kind != want.Kind || len(got.Embedding) != 2 || got.Entities == nil && want.Entities != nil {
+			t.Errorf("memory %d differs:\n got %+v\nwant %+v", i, got, want)
+		}
+	}
+}
+
+func TestImport_RemapDropAndConflicts(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	if err := Export[int](ctx, exportFixture(), &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data := buf.Bytes()
+
+	dst := &mockStore{memories: []Memory[int]{{ID: 101, Content: "existing"}}}
+	remap := func(old string) (int, error) {
+		n, err := strconv.Atoi(old)
+		return n + 100, err
+	}
+	_, err := Import[int](ctx, bytes.NewReader(data), dst, ImportOptions[int]{RemapID: remap})
+	if !errors.Is(err, ErrImportConflict) {
+		t.Fatalf("expected ErrImportConflict, got %v", err)
+	}
+
+	dst = &mockStore{memories: []Memory[int]{{ID: 101, Content: "existing"}}}
+	stats, err := Import[int](ctx, bytes.NewReader(data), dst, ImportOptions[int]{RemapID: remap, Conflict: ConflictSkip, DropEmbeddings: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Imported != 1 || stats.Skipped != 1 || dst.memories[0].Content != "existing" {
+		t.Errorf("unexpected result: %+v %+v", stats, dst.memories)
+	}
+	if got := dst.memories[1]; got.ID != 102 || got.Embedding != nil || got.EmbeddingModel != "" {
+		t.Errorf("expected remapped memory without embedding, got %+v", got)
+	}
+
+	stats, err = Import[int](ctx, bytes.NewReader(data), dst, ImportOptions[int]{RemapID: remap, Conflict: ConflictOverwrite})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Overwritten != 2 || len(dst.memories) != 2 {
+		t.Errorf("unexpected result: %+v %+v", stats, dst.memories)
+	}
+}
+
+func TestImport_RejectsBadInput(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	if err := Export[int](ctx, exportFixture(), &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	full := buf.String()
+	truncated := full[:strings.LastIndex(strings.TrimSpace(full), "\n")+1]
+
+	for name, input := range map[string]string{
+		"not an export":  `{"hello":"world"}`,
+		"newer version":  `{"format":"memai","version":99,"count":0}`,
+		"truncated file": truncated,
+	} {
+		if _, err := Import[int](ctx, strings.NewReader(input), &mockStore{}, ImportOptions[int]{}); !errors.Is(err, ErrImportFormat) {
+			t.Errorf("%s: expected ErrImportFormat, got %v", name, err)
+		}
+	}
+}
+
+func TestExportImport_NamespaceScoped(t *testing.T) {
+	alice := WithNamespace(context.Background(), Namespace{User: "alice"})
+	src := &mockStore{memories: []Memory[int]{
+		{ID: 1, Content: "alice note", Namespace: Namespace{User: "alice"}},
+		{ID: 2, Content: "bob secret", Namespace: Namespace{User: "bob"}},
+	}}
+	var buf bytes.Buffer
+	if err := Export[int](alice, src, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out := buf.String(); strings.Contains(out, "bob secret") || !strings.Contains(out, "alice note") || !strings.Contains(out, `"count":1`) {
+		t.Fatalf("export must only contain alice's memories:\n%s", out)
+	}
+
+	// A full export imported into alice's namespace lands in it.
+	buf.Reset()
+	if err := Export[int](context.Background(), src, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dst := &mockStore{}
+	if _, err := Import[int](alice, bytes.NewReader(buf.Bytes()), dst, ImportOptions[int]{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, mem := range dst.memories {
+		if mem.Namespace != (Namespace{User: "alice"}) {
+			t.Errorf("memory %d imported into %s, want alice", mem.ID, mem.Namespace)
+		}
+	}
+
+	// IDs held by another namespace are never overwritten.
+	dst = &mockStore{memories: []Memory[int]{{ID: 2, Content: "bob secret", Namespace: Namespace{User: "bob"}}}}
+	_, err := Import[int](alice, bytes.NewReader(buf.Bytes()), dst, ImportOptions[int]{Conflict: ConflictOverwrite})
+	if !errors.Is(err, ErrNamespaceMismatch) {
+		t.Fatalf("expected ErrNamespaceMismatch, got %v", err)
+	}
+	for _, mem := range dst.memories {
+		if mem.ID == 2 && (mem.Content != "bob secret" || mem.Namespace != (Namespace{User: "bob"})) {
+			t.Errorf("bob's memory must be untouched, got %+v", mem)
+		}
+	}
+	if len(dst.memories) == 0 || dst.memories[0].ID != 2 {
+		t.Errorf("bob's memory was deleted: %+v", dst.memories)
+	}
+}
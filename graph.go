@@ -0,0 +1,215 @@
+package memai
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// LinkKind classifies why two memories are associated.
+type LinkKind string
+
+const (
+	LinkExplicit     LinkKind = "explicit"      // Created by the application via LTM.Link
+	LinkCoRecalled   LinkKind = "co_recalled"   // Returned together by the same search
+	LinkSameThread   LinkKind = "same_thread"   // Saved in the same conversation thread
+	LinkSharedEntity LinkKind = "shared_entity" // Mention the same person, place or project
+)
+
+const (
+	coRecallWeight     = 0.5
+	sameThreadWeight   = 0.5
+	sharedEntityWeight = 0.7
+)
+
+// MemoryLink is an undirected association between two memories.
+type MemoryLink[ID comparable] struct {
+	From   ID
+	To     ID
+	Kind   LinkKind
+	Weight float64 // Association strength in (0, 1]; 0 is treated as 1
+}
+
+// strength returns the effective weight of the link.
+func (ln MemoryLink[ID]) strength() float64 {
+	if ln.Weight <= 0 {
+		return 1
+	}
+	return ln.Weight
+}
+
+// Link creates an explicit association between two memories. Both must be
+// visible in the LTM's namespace. The store must implement GraphStore.
+func (l *LTM[ID]) Link(ctx context.Context, from, to ID, weight float64) error {
+	if l.graph == nil {
+		return fmt.Errorf("link: %w (GraphStore)", ErrUnsupported)
+	}
+	ctx, ns, err := l.resolveNamespace(ctx, Namespace{})
+	if err != nil {
+		return err
+	}
+	if err := l.checkOwned(ctx, ns, []ID{from, to}); err != nil {
+		return err
+	}
+	link := MemoryLink[ID]{From: from, To: to, Kind: LinkExplicit, Weight: weight}
+	if err := l.graph.AddLinks(ctx, []MemoryLink[ID]{link}); err != nil {
+		return fmt.Errorf("graph store error: %w", err)
+	}
+	return nil
+}
+
+// spread runs spreading activation from the top cosine hits. Activation
+// starts at each seed's score (clamped to [0, 1]) and is multiplied by link
+// weight and SpreadDecay at every hop; a memory keeps the strongest
+// activation it receives. Activated memories already in results get it added
+// to their score, others are appended with the activation as their score.
+// Only memories in the candidate set that pass the query's entity filter and
+// have a compatible embedding can be reached, so namespaces, RequireEntity
+// and embedding models are respected. The results are re-sorted before
+// returning.
+func (l *LTM[ID]) spread(ctx context.Context, sc *scoring, memories []Memory[ID], results []SearchResult[ID]) ([]SearchResult[ID], error) {
+	seeds := min(l.config.SpreadSeeds, len(results))
+	if seeds <= 0 {
+		return results, nil
+	}
+
+	index := make(map[ID]int, len(memories))
+	for i, mem := range memories {
+		if sc.admits(mem.Entities) && l.compatible(mem, len(sc.embedding)) {
+			index[mem.ID] = i
+		}
+	}
+	source := make(map[ID]float64)   // activation a node passes on
+	received := make(map[ID]float64) // activation a non-seed node received
+	var frontier []ID
+	for _, r := range results[:seeds] {
+		source[r.Memory.ID] = max(0, min(1, r.Score))
+		frontier = append(frontier, r.Memory.ID)
+	}
+
+	for hop := 0; hop < l.config.SpreadHops && len(frontier) > 0; hop++ {
+		links, err := l.graph.Links(ctx, frontier)
+		if err != nil {
+			return nil, fmt.Errorf("graph store error: %w", err)
+		}
+		active := make(map[ID]bool, len(frontier))
+		for _, id := range frontier {
+			active[id] = true
+		}
+		frontier = frontier[:0]
+		for _, ln := range links {
+			for _, e := range [2][2]ID{{ln.From, ln.To}, {ln.To, ln.From}} {
+				src, dst := e[0], e[1]
+				if !active[src] {
+					continue
+				}
+				if _, ok := index[dst]; !ok {
+					continue
+				}
+				a := source[src] * ln.strength() * l.config.SpreadDecay
+				if a < l.config.SpreadMinActivation || a <= source[dst] {
+					continue
+				}
+				if _, seen := source[dst]; !seen {
+					frontier = append(frontier, dst)
+				}
+				source[dst] = a
+				received[dst] = a
+			}
+		}
+	}
+
+	pos := make(map[ID]int, len(results))
+	for i, r := range results {
+		pos[r.Memory.ID] = i
+	}
+	// Visit activated memories in store order so ties stay deterministic.
+	activated := make([]ID, 0, len(received))
+	for id := range received {
+		activated = append(activated, id)
+	}
+	sort.Slice(activated, func(i, j int) bool { return index[activated[i]] < index[activated[j]] })
+	for _, id := range activated {
+		a := received[id]
+		if i, ok := pos[id]; ok {
+			results[i].Score += a
+			results[i].Activation = a
+			continue
+		}
+		results = append(results, SearchResult[ID]{Memory: memories[index[id]], Score: a, Activation: a, SearchedAt: sc.now})
+	}
+	sortResults(results)
+	return results, nil
+}
+
+// linkCoRecalled links the top results of a search to each other in the
+// background, when LinkCoRecalled is enabled.
+func (l *LTM[ID]) linkCoRecalled(ctx context.Context, results []SearchResult[ID]) {
+	if !l.config.LinkCoRecalled || l.graph == nil || l.async == nil {
+		return
+	}
+	n := min(max(l.config.SpreadSeeds, 2), len(results))
+	if n < 2 {
+		return
+	}
+	var links []MemoryLink[ID]
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			links = append(links, MemoryLink[ID]{
+				From: results[i].Memory.ID, To: results[j].Memory.ID,
+				Kind: LinkCoRecalled, Weight: coRecallWeight,
+			})
+		}
+	}
+	l.async.enqueue(ctx, func(ctx context.Context) error {
+		return l.graph.AddLinks(ctx, links)
+	})
+}
+
+// autoLink links a newly saved memory to up to ThreadLinks earlier memories
+// of the same thread and up to EntityLinks memories sharing an entity (the
+// most recent in store order).
+func (l *LTM[ID]) autoLink(ctx context.Context, ns Namespace, mem *Memory[ID]) error {
+	if l.graph == nil {
+		return nil
+	}
+	threadLinks := l.config.ThreadLinks
+	if mem.ThreadKey == "" {
+		threadLinks = 0
+	}
+	entityLinks := l.config.EntityLinks
+	entities := entityKeys(mem.Entities)
+	if len(entities) == 0 {
+		entityLinks = 0
+	}
+	if threadLinks <= 0 && entityLinks <= 0 {
+		return nil
+	}
+
+	memories, err := l.memories(ctx, ns)
+	if err != nil {
+		return fmt.Errorf("memory store error: %w", err)
+	}
+	var links []MemoryLink[ID]
+	for i := len(memories) - 1; i >= 0 && (threadLinks > 0 || entityLinks > 0); i-- {
+		other := memories[i]
+		if other.ID == mem.ID {
+			continue
+		}
+		if threadLinks > 0 && other.ThreadKey == mem.ThreadKey {
+			links = append(links, MemoryLink[ID]{From: mem.ID, To: other.ID, Kind: LinkSameThread, Weight: sameThreadWeight})
+			threadLinks--
+		}
+		if entityLinks > 0 && countShared(entities, other.Entities) > 0 {
+			links = append(links, MemoryLink[ID]{From: mem.ID, To: other.ID, Kind: LinkSharedEntity, Weight: sharedEntityWeight})
+			entityLinks--
+		}
+	}
+	if len(links) == 0 {
+		return nil
+	}
+	if err := l.graph.AddLinks(ctx, links); err != nil {
+		return fmt.Errorf("graph store error: %w", err)
+	}
+	return nil
+}
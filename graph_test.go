@@ -0,0 +1,171 @@
+package memai
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// graphStore is a mockStore that also implements GraphStore.
+type graphStore struct {
+	mockStore
+	mu    sync.Mutex
+	links []MemoryLink[int]
+}
+
+func (s *graphStore) AddLinks(_ context.Context, links []MemoryLink[int]) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.links = append(s.links, links...)
+	return nil
+}
+
+func (s *graphStore) Links(_ context.Context, ids []int) ([]MemoryLink[int], error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []MemoryLink[int]
+	for _, ln := range s.links {
+		for _, id := range ids {
+			if ln.From == id || ln.To == id {
+				out = append(out, ln)
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+func (s *graphStore) DeleteLinks(_ context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.links[:0]
+	for _, ln := range s.links {
+		if ln.From != id && ln.To != id {
+			kept = append(kept, ln)
+		}
+	}
+	s.links = kept
+	return nil
+}
+
+func TestLTM_SpreadingActivation(t *testing.T) {
+	store := &graphStore{mockStore: mockStore{
+		memories: []Memory[int]{
+			{ID: 1, Content: "trip to Berlin", Embedding: []float64{1, 0}},
+			{ID: 2, Content: "lost passport", Embedding: []float64{0, 1}},
+			{ID: 3, Content: "unrelated", Embedding: []float64{0, 1}},
+		},
+	}}
+	cfg := DefaultLTMConfig()
+	ltm := NewLTM[int](store, nil, cfg)
+	ctx := context.Background()
+	if err := ltm.Link(ctx, 1, 2, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	q := SearchQuery{QueryEmbedding: []float64{1, 0}}
+
+	results, _ := ltm.Search(ctx, q)
+	if len(results) != 1 {
+		t.Fatalf("without spreading only the cosine hit is returned, got %d", len(results))
+	}
+
+	cfg.SpreadHops = 1
+	results, err := NewLTM[int](store, nil, cfg).Search(ctx, q)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 2 || results[1].Memory.ID != 2 {
+		t.Fatalf("expected the linked memory to be surfaced, got %+v", results)
+	}
+	if results[1].Activation <= 0 || results[0].Activation != 0 {
+		t.Errorf("unexpected activations: %f, %f", results[0].Activation, results[1].Activation)
+	}
+}
+
+func TestLTM_SpreadingDecaysPerHop(t *testing.T) {
+	store := &graphStore{mockStore: mockStore{
+		memories: []Memory[int]{
+			{ID: 1, Embedding: []float64{1, 0}},
+			{ID: 2, Embedding: []float64{0, 1}},
+			{ID: 3, Embedding: []float64{0, 1}},
+		},
+	}}
+	store.links = []MemoryLink[int]{
+		{From: 1, To: 2, Kind: LinkExplicit},
+		{From: 2, To: 3, Kind: LinkExplicit},
+	}
+	cfg := DefaultLTMConfig()
+	cfg.SpreadHops = 2
+	results, err := NewLTM[int](store, nil, cfg).Search(context.Background(), SearchQuery{QueryEmbedding: []float64{1, 0}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 2-hop neighbour to be reached, got %d results", len(results))
+	}
+	if results[1].Activation <= results[2].Activation {
+		t.Errorf("activation should decay with distance: %f <= %f", results[1].Activation, results[2].Activation)
+	}
+}
+
+func TestLTM_SpreadingRespectsFilters(t *testing.T) {
+	store := &graphStore{mockStore: mockStore{
+		memories: []Memory[int]{
+			{ID: 1, Embedding: []float64{1, 0}, Entities: []string{"田中"}},
+			{ID: 2, Embedding: []float64{0, 1}, Entities: []string{"佐藤"}},
+			{ID: 3, Embedding: []float64{0, 1}, Entities: []string{"田中"}},
+			{ID: 4, Embedding: []float64{0, 1, 0}, Entities: []string{"田中"}},
+		},
+	}}
+	store.links = []MemoryLink[int]{
+		{From: 1, To: 2, Kind: LinkExplicit},
+		{From: 1, To: 3, Kind: LinkExplicit},
+		{From: 1, To: 4, Kind: LinkExplicit},
+	}
+	cfg := DefaultLTMConfig()
+	cfg.SpreadHops = 1
+	results, err := NewLTM[int](store, nil, cfg).Search(context.Background(), SearchQuery{
+		QueryEmbedding: []float64{1, 0},
+		Entities:       []string{"田中"},
+		RequireEntity:  true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 2 || results[0].Memory.ID != 1 || results[1].Memory.ID != 3 {
+		t.Errorf("spreading must skip filtered and incompatible memories, got %+v", results)
+	}
+}
+
+func TestLTM_AutoLinks(t *testing.T) {
+	store := &graphStore{mockStore: mockStore{
+		memories: []Memory[int]{
+			{ID: 1, Embedding: []float64{1, 0}, ThreadKey: "t1"},
+			{ID: 2, Embedding: []float64{1, 0.1}, ThreadKey: "t2"},
+		},
+	}}
+	cfg := DefaultLTMConfig()
+	cfg.ThreadLinks = 3
+	cfg.LinkCoRecalled = true
+	ltm := NewLTM[int](store, nil, cfg)
+	ctx := context.Background()
+
+	if err := ltm.Save(ctx, &Memory[int]{ID: 3, Embedding: []float64{0, 1}, ThreadKey: "t1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := ltm.Search(ctx, SearchQuery{QueryEmbedding: []float64{1, 0}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ltm.Close()
+
+	kinds := map[LinkKind]int{}
+	for _, ln := range store.links {
+		kinds[ln.Kind]++
+	}
+	if kinds[LinkSameThread] != 1 {
+		t.Errorf("expected 1 same-thread link, got %d", kinds[LinkSameThread])
+	}
+	if kinds[LinkCoRecalled] != 1 {
+		t.Errorf("expected 1 co-recalled link, got %d", kinds[LinkCoRecalled])
+	}
+}
@@ -0,0 +1,275 @@
+package memai
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MutationKind classifies a change recorded in a HistoryLog.
+type MutationKind string
+
+const (
+	MutationSave      MutationKind = "save"      // Memory saved; Memory holds the saved state
+	MutationBoost     MutationKind = "boost"     // Boost adjusted by BoostDelta
+	MutationContent   MutationKind = "content"   // Content replaced by Content
+	MutationEmbedding MutationKind = "embedding" // Embedding replaced by Embedding
+	MutationDelete    MutationKind = "delete"    // Soft-deleted at DeletedAt
+	MutationRestore   MutationKind = "restore"   // Soft delete undone
+	MutationPurge     MutationKind = "purge"     // Removed from the store
+)
+
+// HistoryEntry is one recorded mutation of a memory. Only the fields of its
+// Kind are set.
+type HistoryEntry[ID comparable] struct {
+	MemoryID  ID
+	Kind      MutationKind
+	Time      time.Time
+	Actor     string    // Who made the change (see WithActor)
+	Reason    string    // Why (see WithActor)
+	Namespace Namespace // Namespace of the request, if any
+
+	Memory         *Memory[ID] // MutationSave
+	BoostDelta     float64     // MutationBoost
+	Content        string      // MutationContent
+	Embedding      []float64   // MutationEmbedding
+	EmbeddingModel string      // MutationEmbedding
+	DeletedAt      time.Time   // MutationDelete
+}
+
+// HistoryLog is an append-only record of memory mutations. Implement this
+// interface to keep the audit trail in a database or log pipeline.
+type HistoryLog[ID comparable] interface {
+	// Append records an entry. Entries are never modified or removed.
+	Append(ctx context.Context, entry HistoryEntry[ID]) error
+	// History returns the entries of one memory in the order they were
+	// appended.
+	History(ctx context.Context, id ID) ([]HistoryEntry[ID], error)
+	// Entries returns every entry with Time at or before until, in the order
+	// they were appended.
+	Entries(ctx context.Context, until time.Time) ([]HistoryEntry[ID], error)
+}
+
+// MemoryHistoryLog is an in-memory HistoryLog. It is safe for concurrent use.
+type MemoryHistoryLog[ID comparable] struct {
+	mu      sync.Mutex
+	entries []HistoryEntry[ID]
+}
+
+// NewMemoryHistoryLog returns an empty in-memory log.
+func NewMemoryHistoryLog[ID comparable]() *MemoryHistoryLog[ID] {
+	return &MemoryHistoryLog[ID]{}
+}
+
+// Append implements HistoryLog.
+func (h *MemoryHistoryLog[ID]) Append(_ context.Context, entry HistoryEntry[ID]) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries = append(h.entries, entry)
+	return nil
+}
+
+// History implements HistoryLog.
+func (h *MemoryHistoryLog[ID]) History(_ context.Context, id ID) ([]HistoryEntry[ID], error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var out []HistoryEntry[ID]
+	for _, e := range h.entries {
+		if e.MemoryID == id {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// Entries implements HistoryLog.
+func (h *MemoryHistoryLog[ID]) Entries(_ context.Context, until time.Time) ([]HistoryEntry[ID], error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var out []HistoryEntry[ID]
+	for _, e := range h.entries {
+		if !e.Time.After(until) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+type actorKey struct{}
+
+type actorInfo struct {
+	actor, reason string
+}
+
+// WithActor returns a context that attributes the mutations made with it to
+// actor (a user, agent or job name) for the given reason.
+func WithActor(ctx context.Context, actor, reason string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actorInfo{actor: actor, reason: reason})
+}
+
+// ActorFromContext returns the actor and reason set with WithActor.
+func ActorFromContext(ctx context.Context) (actor, reason string) {
+	info, _ := ctx.Value(actorKey{}).(actorInfo)
+	return info.actor, info.reason
+}
+
+// AuditOptions configures an AuditedStore.
+type AuditOptions struct {
+	Clock func() time.Time // Time source for entries; nil means time.Now
+}
+
+// AuditedStore is a MemoryStore decorator that appends a HistoryEntry to a
+// HistoryLog after every successful mutation: saves, boosts, content and
+// embedding updates, soft deletes, restores and deletions. The log holds
+// memory content as this decorator sees it; wrap it inside EncryptingStore to
+// log ciphertext only.
+//
+// Logging is not atomic with the mutation: the entry is appended after the
+// wrapped store has changed, so when Append fails the method returns that
+// error although the mutation took effect, and the log misses it. Callers
+// must not retry non-idempotent mutations (SaveMemory, UpdateBoost) on such
+// an error; use a HistoryLog backed by the same transaction as the store
+// when the trail must be complete.
+type AuditedStore[ID comparable] struct {
+	storeDecorator[ID]
+	log  HistoryLog[ID]
+	opts AuditOptions
+}
+
+// NewAuditedStore wraps store so that its mutations are recorded in log.
+// Optional capabilities of store are passed through.
+func NewAuditedStore[ID comparable](store MemoryStore[ID], log HistoryLog[ID], opts AuditOptions) *AuditedStore[ID] {
+	return &AuditedStore[ID]{storeDecorator: storeDecorator[ID]{inner: store}, log: log, opts: opts}
+}
+
+// SaveMemory saves mem and records its state.
+func (s *AuditedStore[ID]) SaveMemory(ctx context.Context, mem *Memory[ID]) error {
+	if err := s.inner.SaveMemory(ctx, mem); err != nil {
+		return err
+	}
+	snapshot := cloneMemory(*mem)
+	return s.record(ctx, HistoryEntry[ID]{MemoryID: mem.ID, Kind: MutationSave, Memory: &snapshot})
+}
+
+// UpdateBoost adjusts the boost and records the delta.
+func (s *AuditedStore[ID]) UpdateBoost(ctx context.Context, id ID, delta float64) error {
+	if err := s.inner.UpdateBoost(ctx, id, delta); err != nil {
+		return err
+	}
+	return s.record(ctx, HistoryEntry[ID]{MemoryID: id, Kind: MutationBoost, BoostDelta: delta})
+}
+
+// DeleteMemory removes a memory and records the removal.
+func (s *AuditedStore[ID]) DeleteMemory(ctx context.Context, id ID) error {
+	if err := s.inner.DeleteMemory(ctx, id); err != nil {
+		return err
+	}
+	return s.record(ctx, HistoryEntry[ID]{MemoryID: id, Kind: MutationPurge})
+}
+
+// UpdateContent replaces the content and records the new content.
+func (s *AuditedStore[ID]) UpdateContent(ctx context.Context, id ID, content string) error {
+	if err := s.storeDecorator.UpdateContent(ctx, id, content); err != nil {
+		return err
+	}
+	return s.record(ctx, HistoryEntry[ID]{MemoryID: id, Kind: MutationContent, Content: content})
+}
+
+// UpdateEmbedding replaces the embedding and records the new embedding.
+func (s *AuditedStore[ID]) UpdateEmbedding(ctx context.Context, id ID, embedding []float64, model string) error {
+	if err := s.storeDecorator.UpdateEmbedding(ctx, id, embedding, model); err != nil {
+		return err
+	}
+	return s.record(ctx, HistoryEntry[ID]{
+		MemoryID: id, Kind: MutationEmbedding,
+		Embedding: slices.Clone(embedding), EmbeddingModel: model,
+	})
+}
+
+// SetDeleted soft-deletes or restores a memory and records it.
+func (s *AuditedStore[ID]) SetDeleted(ctx context.Context, id ID, at time.Time) error {
+	if err := s.storeDecorator.SetDeleted(ctx, id, at); err != nil {
+		return err
+	}
+	if at.IsZero() {
+		return s.record(ctx, HistoryEntry[ID]{MemoryID: id, Kind: MutationRestore})
+	}
+	return s.record(ctx, HistoryEntry[ID]{MemoryID: id, Kind: MutationDelete, DeletedAt: at})
+}
+
+// History returns the recorded mutations of a memory, oldest first.
+func (s *AuditedStore[ID]) History(ctx context.Context, id ID) ([]HistoryEntry[ID], error) {
+	return s.log.History(ctx, id)
+}
+
+// StateAt reconstructs the memories as they were at t by replaying the log,
+// in save order. Soft-deleted memories are included with DeletedAt set.
+// Memories saved before auditing began are unknown to the log and missing.
+func (s *AuditedStore[ID]) StateAt(ctx context.Context, t time.Time) ([]Memory[ID], error) {
+	entries, err := s.log.Entries(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	var order []ID
+	state := make(map[ID]*Memory[ID])
+	for _, e := range entries {
+		if e.Kind == MutationSave {
+			if _, ok := state[e.MemoryID]; !ok {
+				order = append(order, e.MemoryID)
+			}
+			mem := cloneMemory(*e.Memory)
+			state[e.MemoryID] = &mem
+			continue
+		}
+		mem, ok := state[e.MemoryID]
+		if !ok {
+			continue
+		}
+		switch e.Kind {
+		case MutationBoost:
+			mem.Boost += e.BoostDelta
+		case MutationContent:
+			mem.Content = e.Content
+		case MutationEmbedding:
+			mem.Embedding = slices.Clone(e.Embedding)
+			mem.EmbeddingModel = e.EmbeddingModel
+			mem.EmbeddingDim = len(e.Embedding)
+		case MutationDelete:
+			mem.DeletedAt = e.DeletedAt
+		case MutationRestore:
+			mem.DeletedAt = time.Time{}
+		case MutationPurge:
+			delete(state, e.MemoryID)
+		}
+	}
+	var out []Memory[ID]
+	for _, id := range order {
+		if mem, ok := state[id]; ok {
+			out = append(out, *mem)
+			// A purged and re-saved ID is listed once.
+			delete(state, id)
+		}
+	}
+	return out, nil
+}
+
+// record stamps entry with the time, actor, reason and namespace, and
+// appends it to the log.
+func (s *AuditedStore[ID]) record(ctx context.Context, entry HistoryEntry[ID]) error {
+	entry.Time = time.Now()
+	if s.opts.Clock != nil {
+		entry.Time = s.opts.Clock()
+	}
+	entry.Actor, entry.Reason = ActorFromContext(ctx)
+	entry.Namespace, _ = NamespaceFromContext(ctx)
+	return s.log.Append(ctx, entry)
+}
+
+// cloneMemory copies mem with its slices, so later changes to either copy
+// do not affect the other.
+func cloneMemory[ID comparable](mem Memory[ID]) Memory[ID] {
+	mem.Embedding = slices.Clone(mem.Embedding)
+	mem.Entities = slices.Clone(mem.Entities)
+	return mem
+}
@@ -0,0 +1,114 @@
+package memai
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// stepClock returns a clock that advances one minute per call.
+func stepClock(start time.Time) func() time.Time {
+	t := start
+	return func() time.Time {
+		t = t.Add(time.Minute)
+		return t
+	}
+}
+
+func TestAuditedStore_RecordsMutations(t *testing.T) {
+	start := time.Date(2026, 6, 17, 9, 0, 0, 0, time.UTC)
+	inner := &softStore{}
+	log := NewMemoryHistoryLog[int]()
+	store := NewAuditedStore[int](inner, log, AuditOptions{Clock: stepClock(start)})
+	ltm := NewLTM[int](store, nil, DefaultLTMConfig())
+	ctx := WithActor(context.Background(), "agent", "user said 忘れて")
+
+	if err := ltm.Save(ctx, &Memory[int]{ID: 1, Content: "x", Embedding: []float64{1, 0}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ltm.ApplyFeedback(ctx, []int{1}, 0.2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ltm.Delete(ctx, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	history, err := store.History(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	kinds := []MutationKind{MutationSave, MutationBoost, MutationDelete}
+	if len(history) != len(kinds) {
+		t.Fatalf("expected %d entries, got %+v", len(kinds), history)
+	}
+	for i, e := range history {
+		if e.Kind != kinds[i] {
+			t.Errorf("entry %d: kind %s, want %s", i, e.Kind, kinds[i])
+		}
+		if e.Actor != "agent" || e.Reason != "user said 忘れて" {
+			t.Errorf("entry %d: actor %q reason %q", i, e.Actor, e.Reason)
+		}
+		if !e.Time.After(start) {
+			t.Errorf("entry %d: time not set", i)
+		}
+	}
+	if history[1].BoostDelta != 0.2 {
+		t.Errorf("expected boost delta 0.2, got %v", history[1].BoostDelta)
+	}
+}
+
+func TestAuditedStore_StateAt(t *testing.T) {
+	start := time.Date(2026, 6, 17, 9, 0, 0, 0, time.UTC)
+	inner := &contentStore{}
+	store := NewAuditedStore[int](inner, NewMemoryHistoryLog[int](), AuditOptions{Clock: stepClock(start)})
+	ctx := context.Background()
+
+	// One clock step per mutation: 09:01 save 1, 09:02 save 2, 09:03 boost 1,
+	// 09:04 update 1, 09:05 delete 2.
+	if err := store.SaveMemory(ctx, &Memory[int]{ID: 1, Content: "v1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := store.SaveMemory(ctx, &Memory[int]{ID: 2, Content: "other"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := store.UpdateBoost(ctx, 1, 0.5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := store.UpdateContent(ctx, 1, "v2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := store.DeleteMemory(ctx, 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	at := func(min int) []Memory[int] {
+		state, err := store.StateAt(ctx, start.Add(time.Duration(min)*time.Minute))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return state
+	}
+	if got := at(0); len(got) != 0 {
+		t.Errorf("nothing existed at the start, got %+v", got)
+	}
+	if got := at(2); len(got) != 2 || got[0].Content != "v1" || got[0].Boost != 0 {
+		t.Errorf("unexpected state at 09:02: %+v", got)
+	}
+	if got := at(4); len(got) != 2 || got[0].Content != "v2" || got[0].Boost != 0.5 {
+		t.Errorf("unexpected state at 09:04: %+v", got)
+	}
+	if got := at(5); len(got) != 1 || got[0].ID != 1 {
+		t.Errorf("memory 2 should be gone at 09:05: %+v", got)
+	}
+}
+
+func TestAuditedStore_FailedMutationNotRecorded(t *testing.T) {
+	log := NewMemoryHistoryLog[int]()
+	store := NewAuditedStore[int](&mockStore{}, log, AuditOptions{})
+	if err := store.UpdateContent(context.Background(), 1, "x"); err == nil {
+		t.Fatal("expected ErrUnsupported from a store without ContentUpdater")
+	}
+	if entries, _ := log.Entries(context.Background(), time.Now()); len(entries) != 0 {
+		t.Errorf("failed mutation should not be logged: %+v", entries)
+	}
+}
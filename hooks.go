@@ -0,0 +1,84 @@
+package memai
+
+// EvictReason tells why an item left working memory.
+type EvictReason string
+
+const (
+	EvictThreshold EvictReason = "threshold" // Activation fell below ActivationThreshold
+	EvictCapacity  EvictReason = "capacity"  // Pushed out by MaxItems
+)
+
+// STMHooks observes working memory. Every field is optional. Hooks receive
+// copies of the items, in the order the events happened, and run after the
+// STM's lock is released, so a hook may call back into the STM. They run on
+// the goroutine that made the change.
+type STMHooks struct {
+	OnAdd             func(item WorkingMemoryItem)                     // Item added by Add or Ingest
+	OnRefresh         func(item WorkingMemoryItem)                     // Keywords matched; item holds the boosted activation
+	OnMarkedEmotional func(item WorkingMemoryItem)                     // Item became emotional
+	OnEvict           func(item WorkingMemoryItem, reason EvictReason) // Item removed
+	OnDecay           func(item WorkingMemoryItem, before float64)     // Activation decayed from before
+}
+
+// SetHooks installs hooks, replacing any installed before. Hooks are not
+// part of snapshots or checkpoints; install them again on a restored STM.
+func (s *STM) SetHooks(hooks STMHooks) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = hooks
+}
+
+type stmEventKind int
+
+const (
+	eventAdd stmEventKind = iota
+	eventRefresh
+	eventMarkedEmotional
+	eventEvict
+	eventDecay
+)
+
+// stmEvent is a change recorded under the lock and delivered after it.
+type stmEvent struct {
+	kind   stmEventKind
+	item   WorkingMemoryItem
+	reason EvictReason
+	before float64
+}
+
+// emit records an event for item if a hook for it is installed. The caller
+// holds s.mu.
+func (s *STM) emit(kind stmEventKind, item *WorkingMemoryItem, reason EvictReason, before float64) {
+	h := &s.hooks
+	switch {
+	case kind == eventAdd && h.OnAdd == nil,
+		kind == eventRefresh && h.OnRefresh == nil,
+		kind == eventMarkedEmotional && h.OnMarkedEmotional == nil,
+		kind == eventEvict && h.OnEvict == nil,
+		kind == eventDecay && h.OnDecay == nil:
+		return
+	}
+	s.events = append(s.events, stmEvent{kind: kind, item: cloneItem(item), reason: reason, before: before})
+}
+
+// unlock releases s.mu and then delivers the events recorded while it was
+// held.
+func (s *STM) unlock() {
+	events, hooks := s.events, s.hooks
+	s.events = nil
+	s.mu.Unlock()
+	for _, e := range events {
+		switch e.kind {
+		case eventAdd:
+			hooks.OnAdd(e.item)
+		case eventRefresh:
+			hooks.OnRefresh(e.item)
+		case eventMarkedEmotional:
+			hooks.OnMarkedEmotional(e.item)
+		case eventEvict:
+			hooks.OnEvict(e.item, e.reason)
+		case eventDecay:
+			hooks.OnDecay(e.item, e.before)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package memai
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestSTMHooks_Lifecycle(t *testing.T) {
+	config := DefaultSTMConfig()
+	config.MaxItems = 2
+	stm := NewSTM(config)
+
+	var log []string
+	stm.SetHooks(STMHooks{
+		OnAdd:             func(it WorkingMemoryItem) { log = append(log, "add "+it.Topic) },
+		OnRefresh:         func(it WorkingMemoryItem) { log = append(log, fmt.Sprintf("refresh %s %.2f", it.Topic, it.Activation)) },
+		OnMarkedEmotional: func(it WorkingMemoryItem) { log = append(log, "emotional "+it.Topic) },
+		OnEvict:           func(it WorkingMemoryItem, r EvictReason) { log = append(log, fmt.Sprintf("evict %s %s", it.Topic, r)) },
+		OnDecay: func(it WorkingMemoryItem, before float64) {
+			log = append(log, fmt.Sprintf("decay %s %.2f->%.2f", it.Topic, before, it.Activation))
+		},
+	})
+
+	stm.Add(&WorkingMemoryItem{Topic: "trip", Keywords: []string{"京都"}, Activation: 0.5})
+	stm.Add(&WorkingMemoryItem{Topic: "work", Keywords: []string{"締切"}, Activation: 0.2})
+	stm.Update(1, "京都が楽しみ", &EmotionalState{Primary: EmotionJoy, Intensity: 0.8})
+	stm.Add(&WorkingMemoryItem{Topic: "food", Keywords: []string{"ラーメン"}, Activation: 0.9})
+	stm.Add(&WorkingMemoryItem{Topic: "music", Keywords: []string{"ライブ"}, Activation: 0.8})
+
+	want := []string{
+		"add trip",
+		"add work",
+		"decay trip 0.50->0.35",
+		"decay work 0.20->0.05",
+		"emotional trip",
+		"refresh trip 0.65",
+		"evict work threshold",
+		"add food",
+		"add music",
+		"evict trip capacity",
+	}
+	if !reflect.DeepEqual(log, want) {
+		t.Errorf("events:\n got %q\nwant %q", log, want)
+	}
+}
+
+func TestSTMHooks_CanCallBack(t *testing.T) {
+	stm := NewSTM(DefaultSTMConfig())
+	var seen int
+	stm.SetHooks(STMHooks{
+		OnAdd: func(WorkingMemoryItem) {
+			// Would deadlock if hooks ran under the lock.
+			seen = len(stm.Items())
+		},
+	})
+	stm.Add(&WorkingMemoryItem{Topic: "A", Keywords: []string{"a"}, Activation: 1})
+	if seen != 1 {
+		t.Errorf("hook should see the added item, saw %d items", seen)
+	}
+}
+
+func TestSTMHooks_Ingest(t *testing.T) {
+	stm := NewSTM(DefaultSTMConfig())
+	var added []string
+	stm.SetHooks(STMHooks{OnAdd: func(it WorkingMemoryItem) { added = append(added, it.Topic) }})
+	stm.Ingest(1, "プロジェクト会議の資料", nil)
+	if !reflect.DeepEqual(added, []string{"プロジェクト会議の資料"}) {
+		t.Errorf("OnAdd should report ingested topics, got %q", added)
+	}
+}
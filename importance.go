@@ -0,0 +1,146 @@
+package memai
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+// ImportanceInput describes a memory about to be saved, for importance
+// estimation.
+type ImportanceInput struct {
+	Content  string
+	Entities []string
+	// Novelty is 1 minus the highest cosine similarity to an existing memory
+	// in the same namespace (1 when there is nothing comparable). It is 0
+	// unless LTMConfig.ImportanceNovelty is set.
+	Novelty float64
+}
+
+// ImportanceEstimator scores how important a new memory is, from 0.0 (trivia)
+// to 1.0 (must not be forgotten). Implement this interface to plug in an
+// LLM-based judgement.
+type ImportanceEstimator interface {
+	EstimateImportance(ctx context.Context, in ImportanceInput) (float64, error)
+}
+
+// Heuristic importance weights. They sum to more than 1 so a memory with
+// several strong signals saturates at 1.
+const (
+	importanceRememberCue   = 0.35
+	importancePersonalFact  = 0.25
+	importanceDate          = 0.15
+	importanceNumber        = 0.1
+	importanceNamedEntities = 0.15
+	importanceNovelty       = 0.2
+)
+
+// rememberCues are explicit requests to remember something. Japanese cues
+// are matched as substrings, English ones as whole words (see
+// englishCueMatcher).
+var (
+	rememberCuesJA = []string{"覚えて", "忘れないで", "忘れずに", "メモして", "記録して", "大事なこと"}
+	rememberCuesEN = []string{"remember", "don't forget", "dont forget", "keep in mind", "note that", "important"}
+)
+
+// personalFactPatterns signal a durable fact about the user, matched like
+// rememberCues.
+var (
+	personalFactPatternsJA = []string{
+		"私の", "僕の", "俺の", "私は", "僕は", "俺は", "うちの",
+		"アレルギー", "誕生日", "住んで", "名前", "家族", "持病", "苦手", "好きな",
+	}
+	personalFactPatternsEN = []string{
+		"my", "i am", "i'm", "allergic", "allergy", "birthday",
+		"i live", "i lived", "i work", "i worked", "i hate", "i love",
+		"i can't eat", "i cant eat",
+	}
+)
+
+// englishCueMatcher matches English cues as exact words in sequence, so "my"
+// does not match "economy" and "important" does not match "imported".
+// Inflections that should count are listed as cues of their own.
+var englishCueMatcher = NewEnglishMatcher(false)
+
+var (
+	datePattern   = regexp.MustCompile(`\d{1,4}[/-]\d{1,2}([/-]\d{1,4})?|\d{1,2}月\d{1,2}日|\d{4}年|(?i)\b(jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.? \d{1,2}\b`)
+	numberPattern = regexp.MustCompile(`[0-9０-９]`)
+)
+
+// HeuristicImportanceEstimator implements ImportanceEstimator with keyword
+// and pattern rules for Japanese and English. It adds up signals for explicit
+// "remember this" cues, personal facts, dates, numbers, named entities and
+// novelty against existing memories, capped at 1.0.
+type HeuristicImportanceEstimator struct {
+	entities EntityExtractor
+}
+
+// NewHeuristicImportanceEstimator returns the built-in estimator. Entities
+// are taken from ImportanceInput.Entities, or extracted with the heuristic
+// EntityExtractor when none are given.
+func NewHeuristicImportanceEstimator() *HeuristicImportanceEstimator {
+	return &HeuristicImportanceEstimator{entities: NewHeuristicEntityExtractor()}
+}
+
+// EstimateImportance implements ImportanceEstimator. It never returns an
+// error; the ctx argument satisfies the interface for drop-in LLM replacement.
+func (e *HeuristicImportanceEstimator) EstimateImportance(_ context.Context, in ImportanceInput) (float64, error) {
+	score := 0.0
+	if containsAny(in.Content, rememberCuesJA) || englishCueMatcher.MatchKeywords(in.Content, rememberCuesEN) {
+		score += importanceRememberCue
+	}
+	if containsAny(in.Content, personalFactPatternsJA) || englishCueMatcher.MatchKeywords(in.Content, personalFactPatternsEN) {
+		score += importancePersonalFact
+	}
+	if datePattern.MatchString(in.Content) {
+		score += importanceDate
+	} else if numberPattern.MatchString(in.Content) {
+		score += importanceNumber
+	}
+	entities := in.Entities
+	if len(entities) == 0 {
+		entities = e.entities.ExtractEntities(in.Content)
+	}
+	if len(entities) > 0 {
+		score += importanceNamedEntities
+	}
+	score += importanceNovelty * max(0, min(1, in.Novelty))
+	return min(score, 1), nil
+}
+
+// estimateImportance fills mem.Importance using the configured estimator,
+// unless it is already set. Novelty is only measured under
+// ImportanceNovelty, since it reads every memory in the namespace.
+func (l *LTM[ID]) estimateImportance(ctx context.Context, ns Namespace, mem *Memory[ID]) error {
+	if l.config.ImportanceEstimator == nil || mem.Importance != 0 {
+		return nil
+	}
+	novelty := 0.0
+	if l.config.ImportanceNovelty && len(mem.Embedding) > 0 {
+		novelty = 1
+		existing, err := l.memories(ctx, ns)
+		if err != nil {
+			return fmt.Errorf("memory store error: %w", err)
+		}
+		norm := vectorNorm(mem.Embedding)
+		for _, other := range existing {
+			if other.ID == mem.ID || !l.compatible(other, len(mem.Embedding)) {
+				continue
+			}
+			sim := cosineWithNorm(mem.Embedding, other.Embedding, norm)
+			if novelty = min(novelty, 1-sim); novelty <= 0 {
+				break
+			}
+		}
+	}
+	importance, err := l.config.ImportanceEstimator.EstimateImportance(ctx, ImportanceInput{
+		Content:  mem.Content,
+		Entities: mem.Entities,
+		Novelty:  novelty,
+	})
+	if err != nil {
+		return fmt.Errorf("importance estimation failed: %w", err)
+	}
+	mem.Importance = max(0, min(1, importance))
+	return nil
+}
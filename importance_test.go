@@ -0,0 +1,96 @@
+package memai
+
+import (
+	"context"
+	"testing"
+)
+
+func TestHeuristicImportance(t *testing.T) {
+	e := NewHeuristicImportanceEstimator()
+	ctx := context.Background()
+	score := func(content string) float64 {
+		v, err := e.EstimateImportance(ctx, ImportanceInput{Content: content})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return v
+	}
+
+	trivia := score("いい天気だね")
+	cases := []string{
+		"私のアレルギーはピーナッツ",
+		"覚えておいて、会議は5月3日",
+		"Remember my allergy is peanuts",
+		"Anna's birthday is May 3",
+	}
+	for _, c := range cases {
+		if got := score(c); got <= trivia+0.2 {
+			t.Errorf("%q should be clearly more important than small talk (%f), got %f", c, trivia, got)
+		}
+	}
+	for _, c := range []string{"the economy is slowing", "wifi works again", "that was unimportant", "we imported the data", "the importer crashed"} {
+		if got := score(c); got != trivia {
+			t.Errorf("%q has no cue and should score like small talk (%f), got %f", c, trivia, got)
+		}
+	}
+	if got := score("I worked at a bakery"); got <= trivia {
+		t.Errorf("listed inflection should count, got %f", got)
+	}
+	if got := score("覚えて！私の誕生日は5月3日、田中さんにも伝えて"); got > 1 {
+		t.Errorf("importance must be capped at 1, got %f", got)
+	}
+}
+
+func TestLTM_SaveEstimatesImportance(t *testing.T) {
+	store := &mockStore{
+		memories: []Memory[int]{{ID: 1, Content: "dup", Embedding: []float64{1, 0}}},
+	}
+	cfg := DefaultLTMConfig()
+	cfg.ImportanceEstimator = NewHeuristicImportanceEstimator()
+	cfg.ImportanceNovelty = true
+	ltm := NewLTM(store, nil, cfg)
+	ctx := context.Background()
+
+	if err := ltm.Save(ctx, &Memory[int]{ID: 2, Content: "hello", Embedding: []float64{1, 0}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ltm.Save(ctx, &Memory[int]{ID: 3, Content: "hello", Embedding: []float64{0, 1}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dup, novel := store.memories[1].Importance, store.memories[2].Importance
+	if novel <= dup {
+		t.Errorf("a novel memory should score higher than a duplicate: %f <= %f", novel, dup)
+	}
+}
+
+func TestLTM_SaveSkipsNoveltyByDefault(t *testing.T) {
+	store := &lookupStore{}
+	cfg := DefaultLTMConfig()
+	cfg.ImportanceEstimator = NewHeuristicImportanceEstimator()
+	ltm := NewLTM(store, nil, cfg)
+	if err := ltm.Save(context.Background(), &Memory[int]{ID: 1, Content: "remember this", Embedding: []float64{1, 0}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if store.scans != 0 {
+		t.Errorf("Save should not read the store without ImportanceNovelty, got %d scans", store.scans)
+	}
+	if got := store.memories[0].Importance; got != importanceRememberCue {
+		t.Errorf("importance should come from the cue alone, got %f", got)
+	}
+}
+
+func TestLTM_ImportanceBoostsRanking(t *testing.T) {
+	store := &mockStore{
+		memories: []Memory[int]{
+			{ID: 1, Content: "plain", Embedding: []float64{1, 0}},
+			{ID: 2, Content: "important", Embedding: []float64{1, 0}, Importance: 0.9},
+		},
+	}
+	results, err := NewLTM(store, nil, DefaultLTMConfig()).Search(context.Background(), SearchQuery{QueryEmbedding: []float64{1, 0}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if results[0].Memory.Content != "important" {
+		t.Errorf("important memory should rank first, got %q", results[0].Memory.Content)
+	}
+}
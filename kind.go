@@ -0,0 +1,76 @@
+package memai
+
+import (
+	"math"
+	"time"
+)
+
+// KindPolicy scales the context-dependent ranking factors for one
+// MemoryKind. Each factor multiplies the corresponding LTMConfig value:
+// 1 applies it fully, 0 ignores it.
+type KindPolicy struct {
+	ThreadFactor      float64 // Multiplier for ThreadBoost
+	DateBoostFactor   float64 // Multiplier for DateBoost
+	DatePenaltyFactor float64 // Multiplier for DatePenalty
+	RecencyFactor     float64 // Multiplier for RecencyPenalty
+}
+
+// fullPolicy applies every factor at full strength.
+var fullPolicy = KindPolicy{ThreadFactor: 1, DateBoostFactor: 1, DatePenaltyFactor: 1, RecencyFactor: 1}
+
+// DefaultKindPolicies returns the default per-kind policies. Episodic
+// memories use thread, date and recency fully. Semantic facts are not tied to
+// a conversation or a moment: a date mismatch never penalizes them, they do
+// not fade with age, and the thread they were learned in does not matter.
+func DefaultKindPolicies() map[MemoryKind]KindPolicy {
+	return map[MemoryKind]KindPolicy{
+		KindEpisodic: fullPolicy,
+		KindSemantic: {ThreadFactor: 0, DateBoostFactor: 1, DatePenaltyFactor: 0, RecencyFactor: 0},
+	}
+}
+
+// policy returns the ranking policy for kind ("" is KindEpisodic).
+func (l *LTM[ID]) policy(kind MemoryKind) KindPolicy {
+	if kind == "" {
+		kind = KindEpisodic
+	}
+	if p, ok := l.config.Kinds[kind]; ok {
+		return p
+	}
+	return fullPolicy
+}
+
+// recencyDelta returns the ranking adjustment for a memory's age. It grows
+// from 0 towards RecencyPenalty, reaching half of it at RecencyHalfLife. An
+// important memory is retained longer: its half-life is stretched by up to 2x
+// at Importance 1. It is zero when the creation time is unknown.
+func (l *LTM[ID]) recencyDelta(now time.Time, mem Memory[ID]) float64 {
+	if l.config.RecencyPenalty == 0 || l.config.RecencyHalfLife <= 0 || mem.CreatedAt.IsZero() {
+		return 0
+	}
+	age := now.Sub(mem.CreatedAt)
+	if age <= 0 {
+		return 0
+	}
+	halfLife := float64(l.config.RecencyHalfLife) * (1 + mem.Importance)
+	return l.config.RecencyPenalty * (1 - math.Exp2(-float64(age)/halfLife))
+}
+
+// truncate applies TopK to sorted results. With AlwaysIncludeSemantic, the
+// semantic facts that fall beyond TopK are kept as well, after the top K.
+func (l *LTM[ID]) truncate(results []SearchResult[ID]) []SearchResult[ID] {
+	k := l.config.TopK
+	if k <= 0 || len(results) <= k {
+		return results
+	}
+	if !l.config.AlwaysIncludeSemantic {
+		return results[:k]
+	}
+	out := results[:k:k]
+	for _, r := range results[k:] {
+		if r.Memory.Kind == KindSemantic {
+			out = append(out, r)
+		}
+	}
+	return out
+}
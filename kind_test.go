@@ -0,0 +1,80 @@
+package memai
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLTM_SemanticIgnoresDatePenalty(t *testing.T) {
+	store := &mockStore{
+		memories: []Memory[int]{
+			{ID: 1, Content: "event", Embedding: []float64{1, 0}, EventDate: "2026-05-01"},
+			{ID: 2, Content: "fact", Embedding: []float64{1, 0}, EventDate: "2026-05-01", Kind: KindSemantic},
+		},
+	}
+	results, err := NewLTM(store, nil, DefaultLTMConfig()).Search(context.Background(), SearchQuery{
+		QueryEmbedding: []float64{1, 0},
+		QueryDate:      "2026-06-17",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 2 || results[0].Memory.Content != "fact" {
+		t.Fatalf("fact should outrank the date-penalized event, got %+v", results)
+	}
+	if results[0].Score != 1 {
+		t.Errorf("fact should not be penalized, got score %f", results[0].Score)
+	}
+}
+
+func TestLTM_RecencyDecayEpisodicOnly(t *testing.T) {
+	now := time.Date(2026, 6, 17, 0, 0, 0, 0, time.UTC)
+	old := now.Add(-90 * 24 * time.Hour)
+	store := &mockStore{
+		memories: []Memory[int]{
+			{ID: 1, Content: "old-event", Embedding: []float64{1, 0}, CreatedAt: old},
+			{ID: 2, Content: "old-fact", Embedding: []float64{1, 0}, CreatedAt: old, Kind: KindSemantic},
+			{ID: 3, Content: "new-event", Embedding: []float64{1, 0}, CreatedAt: now},
+		},
+	}
+	cfg := DefaultLTMConfig()
+	cfg.RecencyPenalty = -0.2
+	cfg.Clock = func() time.Time { return now }
+	results, err := NewLTM(store, nil, cfg).Search(context.Background(), SearchQuery{QueryEmbedding: []float64{1, 0}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if results[2].Memory.Content != "old-event" {
+		t.Errorf("old episodic memory should rank last, got %+v", results)
+	}
+	for _, r := range results[:2] {
+		if r.Score != 1 {
+			t.Errorf("%s should not decay, got %f", r.Memory.Content, r.Score)
+		}
+	}
+}
+
+func TestLTM_AlwaysIncludeSemantic(t *testing.T) {
+	store := &mockStore{
+		memories: []Memory[int]{
+			{ID: 1, Embedding: []float64{1, 0}},
+			{ID: 2, Embedding: []float64{1, 0.1}},
+			{ID: 3, Embedding: []float64{1, 0.5}, Kind: KindSemantic},
+		},
+	}
+	cfg := DefaultLTMConfig()
+	cfg.TopK = 1
+	q := SearchQuery{QueryEmbedding: []float64{1, 0}}
+
+	results, _ := NewLTM(store, nil, cfg).Search(context.Background(), q)
+	if len(results) != 1 {
+		t.Fatalf("expected TopK=1 results, got %d", len(results))
+	}
+
+	cfg.AlwaysIncludeSemantic = true
+	results, _ = NewLTM(store, nil, cfg).Search(context.Background(), q)
+	if len(results) != 2 || results[1].Memory.ID != 3 {
+		t.Errorf("semantic fact should be appended beyond TopK, got %+v", results)
+	}
+}
@@ -2,6 +2,7 @@ package memai
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"sort"
@@ -14,7 +15,8 @@ import (
 // by EmotionalPrimeDelta when the user is emotional). The remaining factors
 // (feedback Boost, emotion, thread, date) only adjust the score used for
 // ranking the included results; they never resurrect a semantically irrelevant
-// memory.
+// memory. The one deliberate exception is spreading activation (SpreadHops),
+// which admits memories linked to the top hits through a GraphStore.
 type LTMConfig struct {
 	SimilarityThreshold float64 // Minimum cosine similarity to include (default: 0.3)
 	TopK                int     // Maximum results to return; <= 0 means no limit (default: 10)
@@ -23,8 +25,82 @@ type LTMConfig struct {
 	DatePenalty         float64 // Ranking penalty for mismatched date (default: -0.2)
 	EmotionalBoost      float64 // Ranking boost factor for emotional memories (default: 0.12)
 	EmotionalPrimeDelta float64 // Threshold reduction when user is emotional (default: 0.05)
+	Workers             int     // Goroutines used to score memories; <= 0 means GOMAXPROCS (default: 0)
+
+	// Kinds holds the ranking policy of each MemoryKind. Kinds missing from
+	// the map get every factor at full strength (default: DefaultKindPolicies()).
+	Kinds                 map[MemoryKind]KindPolicy
+	RecencyPenalty        float64       // Ranking penalty approached as memories age; 0 disables it (default: 0)
+	RecencyHalfLife       time.Duration // Age at which half of RecencyPenalty applies (default: 30 days)
+	AlwaysIncludeSemantic bool          // Return every matching semantic fact even beyond TopK (default: false)
+
+	// EmbeddingModel identifies the model behind the embedding function.
+	// When set, memories tagged with a different model are treated as
+	// incompatible with the query. "" disables the model check (dimension is
+	// always checked).
+	EmbeddingModel string
+	// Incompatible selects how Search treats memories whose embedding cannot
+	// be compared with the query (default: IncompatibleSkip).
+	Incompatible IncompatiblePolicy
+
+	// TrackAccess records an AccessEvent per returned memory after every
+	// Search, when the store implements AccessStore. Events are written
+	// asynchronously; call LTM.Close to flush them on shutdown. Co-recall
+	// links (LinkCoRecalled) share the same background writer and queue.
+	TrackAccess        bool
+	AccessQueueSize    int         // Pending background writes buffered (default: 256)
+	AccessErrorHandler func(error) // Receives asynchronous write errors; nil ignores them
+
+	// Spreading activation: the top SpreadSeeds cosine hits pass activation
+	// along graph links (see GraphStore) for up to SpreadHops hops, decaying
+	// by SpreadDecay per hop, so associated memories can be recalled even
+	// when they are not semantically close to the query.
+	SpreadHops          int     // Hops activation travels; 0 disables spreading (default: 0)
+	SpreadSeeds         int     // Top cosine hits used as activation sources (default: 5)
+	SpreadDecay         float64 // Fraction of activation passed on per hop (default: 0.5)
+	SpreadMinActivation float64 // Neighbours receiving less are ignored (default: 0.1)
+	LinkCoRecalled      bool    // Link the top SpreadSeeds results of each search to each other (default: false)
+	ThreadLinks         int     // On Save, link to up to this many same-thread memories (default: 0)
+
+	// EntityExtractor tags memories with entities on Save and extracts them
+	// from SearchQuery.Query when SearchQuery.Entities is empty. nil disables
+	// extraction (explicit entities still work).
+	EntityExtractor EntityExtractor
+	EntityBoost     float64 // Ranking boost per entity shared with the query (default: 0.1)
+	EntityLinks     int     // On Save, link to up to this many memories sharing an entity (default: 0)
+
+	// ImportanceEstimator scores new memories on Save when Importance is
+	// unset. nil disables estimation.
+	ImportanceEstimator ImportanceEstimator
+	ImportanceBoost     float64 // Ranking boost factor for important memories (default: 0.1)
+	ImportanceNovelty   bool    // Compare new memories with the whole namespace for novelty; O(N) per Save (default: false)
+
+	// Redactor removes PII from memory content and entities on Save, before
+	// the embedding is computed, and from query text on Search. nil disables
+	// redaction.
+	Redactor *Redactor
+
+	Clock func() time.Time // Time source; nil means time.Now
 }
 
+// IncompatiblePolicy controls how LTM.Search handles memories whose embedding
+// was produced by a different model or has a different dimension than the
+// query embedding.
+type IncompatiblePolicy string
+
+const (
+	// IncompatibleSkip silently excludes incompatible memories.
+	IncompatibleSkip IncompatiblePolicy = "skip"
+	// IncompatibleError makes Search fail with ErrIncompatibleEmbedding when
+	// any incompatible memory is found, so a pending migration is noticed.
+	IncompatibleError IncompatiblePolicy = "error"
+)
+
+// ErrIncompatibleEmbedding is returned by Search under IncompatibleError when
+// some stored embeddings cannot be compared with the query embedding. Run
+// LTM.Reembed to migrate them.
+var ErrIncompatibleEmbedding = errors.New("memai: incompatible embeddings in store")
+
 // DefaultLTMConfig returns the default long-term memory configuration.
 func DefaultLTMConfig() LTMConfig {
 	return LTMConfig{
@@ -35,6 +111,14 @@ func DefaultLTMConfig() LTMConfig {
 		DatePenalty:         -0.2,
 		EmotionalBoost:      0.12,
 		EmotionalPrimeDelta: 0.05,
+		Kinds:               DefaultKindPolicies(),
+		RecencyHalfLife:     30 * 24 * time.Hour,
+		Incompatible:        IncompatibleSkip,
+		SpreadSeeds:         5,
+		SpreadDecay:         0.5,
+		SpreadMinActivation: 0.1,
+		EntityBoost:         0.1,
+		ImportanceBoost:     0.1,
 	}
 }
 
@@ -43,85 +127,233 @@ type LTM[ID comparable] struct {
 	config    LTMConfig
 	store     MemoryStore[ID]
 	embedding EmbeddingFunc
+	namespace Namespace
+
+	accessStore AccessStore[ID]     // nil unless TrackAccess is on and supported
+	graph       GraphStore[ID]      // nil unless the store supports links
+	softDelete  SoftDeleteStore[ID] // nil unless the store supports tombstones
+	lookup      NamespaceLookup[ID] // nil unless the store can look up namespaces
+	async       *asyncWriter        // nil unless a background feature is on
 }
 
-// NewLTM creates a new long-term memory manager.
+// NewLTM creates a new long-term memory manager. Optional store capabilities
+// (AccessStore, GraphStore) are detected here. When a feature that writes in
+// the background is enabled (TrackAccess, LinkCoRecalled), a background
+// goroutine is started; release it with Close.
 func NewLTM[ID comparable](store MemoryStore[ID], embeddingFn EmbeddingFunc, config LTMConfig) *LTM[ID] {
-	return &LTM[ID]{
+	l := &LTM[ID]{
 		config:    config,
 		store:     store,
 		embedding: embeddingFn,
 	}
+	if as, ok := capability[AccessStore[ID]](store); ok && config.TrackAccess {
+		l.accessStore = as
+	}
+	if gs, ok := capability[GraphStore[ID]](store); ok {
+		l.graph = gs
+	}
+	if ss, ok := capability[SoftDeleteStore[ID]](store); ok {
+		l.softDelete = ss
+	}
+	if nl, ok := capability[NamespaceLookup[ID]](store); ok {
+		l.lookup = nl
+	}
+	if l.accessStore != nil || (l.graph != nil && config.LinkCoRecalled) {
+		l.async = newAsyncWriter(config.AccessQueueSize, config.AccessErrorHandler)
+	}
+	return l
+}
+
+// view returns a copy of l bound to ns. Views share the store and
+// background writer of l.
+func (l *LTM[ID]) view(ns Namespace) *LTM[ID] {
+	v := *l
+	v.namespace = ns
+	return &v
+}
+
+// now returns the current time from the configured clock.
+func (l *LTM[ID]) now() time.Time {
+	if l.config.Clock != nil {
+		return l.config.Clock()
+	}
+	return time.Now()
 }
 
 // Search finds relevant memories for the given query using vector similarity
 // with multi-factor scoring (thread, date, emotion) and emotional priming.
 func (l *LTM[ID]) Search(ctx context.Context, q SearchQuery) ([]SearchResult[ID], error) {
+	ctx, ns, err := l.resolveNamespace(ctx, q.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if l.config.Redactor != nil && q.Query != "" {
+		if q.Query, err = l.config.Redactor.redact(ctx, q.Query, true); err != nil {
+			return nil, err
+		}
+	}
+
 	// Generate embedding if not provided
 	queryEmb := q.QueryEmbedding
 	if len(queryEmb) == 0 {
 		if l.embedding == nil {
 			return nil, fmt.Errorf("no embedding function and no query embedding provided")
 		}
-		var err error
 		queryEmb, err = l.embedding(ctx, q.Query)
 		if err != nil {
 			return nil, fmt.Errorf("embedding generation failed: %w", err)
 		}
 	}
 
-	memories, err := l.store.GetMemories(ctx)
+	memories, err := l.memories(ctx, ns)
 	if err != nil {
 		return nil, fmt.Errorf("memory store error: %w", err)
 	}
 
+	sc := &scoring{
+		query:     q,
+		embedding: queryEmb,
+		norm:      vectorNorm(queryEmb),
+		threshold: l.config.SimilarityThreshold,
+		entities:  entityKeys(l.queryEntities(q)),
+		now:       l.now(),
+	}
 	// Emotional priming: lower threshold when user is emotional
-	threshold := l.config.SimilarityThreshold
 	if q.EmotionalIntensity > 0.5 {
-		threshold -= l.config.EmotionalPrimeDelta
+		sc.threshold -= l.config.EmotionalPrimeDelta
 	}
 
-	var results []SearchResult[ID]
-	for _, mem := range memories {
-		if len(mem.Embedding) == 0 {
-			continue
-		}
-
-		// Inclusion is decided by cosine similarity alone (with emotional
-		// priming); the boosts below only affect ranking.
-		sim := CosineSimilarity(queryEmb, mem.Embedding)
-		if sim < threshold {
-			continue
-		}
-
-		score := sim
+	results, incompatible, err := l.scan(ctx, sc, memories)
+	if err != nil {
+		return nil, err
+	}
 
-		// Feedback boost
-		score += mem.Boost
+	if incompatible > 0 && l.config.Incompatible == IncompatibleError {
+		return nil, fmt.Errorf("%w: %d memories", ErrIncompatibleEmbedding, incompatible)
+	}
 
-		// Emotional boost
-		score += l.config.EmotionalBoost * mem.EmotionalIntensity
+	sortResults(results)
 
-		// Thread boost
-		if q.ThreadKey != "" && mem.ThreadKey == q.ThreadKey {
-			score += l.config.ThreadBoost
+	if l.config.SpreadHops > 0 && l.graph != nil {
+		results, err = l.spread(ctx, sc, memories, results)
+		if err != nil {
+			return nil, err
 		}
+	}
 
-		// Date boost/penalty (ranking only)
-		score += l.dateDelta(q, mem)
+	results = l.truncate(results)
 
-		results = append(results, SearchResult[ID]{Memory: mem, Score: score})
-	}
+	l.recordAccess(ctx, ns, q.Query, results)
+	l.linkCoRecalled(ctx, results)
+	return results, nil
+}
 
+// sortResults orders results by descending score, keeping store order among
+// equal scores.
+func sortResults[ID comparable](results []SearchResult[ID]) {
 	sort.SliceStable(results, func(i, j int) bool {
 		return results[i].Score > results[j].Score
 	})
+}
+
+// score computes the ranking score of a memory that passed the similarity
+// gate. Boosts only affect ranking, never inclusion.
+func (l *LTM[ID]) score(sc *scoring, mem Memory[ID], sim float64) float64 {
+	q := sc.query
+	score := sim
+
+	// Feedback boost
+	score += mem.Boost
 
-	if l.config.TopK > 0 && len(results) > l.config.TopK {
-		results = results[:l.config.TopK]
+	// Emotional boost
+	score += l.config.EmotionalBoost * mem.EmotionalIntensity
+
+	// Importance boost
+	score += l.config.ImportanceBoost * mem.Importance
+
+	policy := l.policy(mem.Kind)
+
+	// Thread boost
+	if q.ThreadKey != "" && mem.ThreadKey == q.ThreadKey {
+		score += l.config.ThreadBoost * policy.ThreadFactor
 	}
 
-	return results, nil
+	// Date boost/penalty (ranking only)
+	if d := l.dateDelta(q, mem); d > 0 {
+		score += d * policy.DateBoostFactor
+	} else {
+		score += d * policy.DatePenaltyFactor
+	}
+
+	// Recency decay
+	score += l.recencyDelta(sc.now, mem) * policy.RecencyFactor
+
+	// Entity overlap boost
+	score += l.config.EntityBoost * float64(sc.sharedEntities(mem.Entities))
+
+	return score
+}
+
+// compatible reports whether mem's embedding can be compared with a query
+// embedding of dimension dim produced by the configured model. Untagged
+// memories (EmbeddingModel == "") are only checked for dimension, so stores
+// written before model tagging keep working.
+func (l *LTM[ID]) compatible(mem Memory[ID], dim int) bool {
+	if len(mem.Embedding) != dim {
+		return false
+	}
+	if mem.EmbeddingDim != 0 && mem.EmbeddingDim != len(mem.Embedding) {
+		return false
+	}
+	if l.config.EmbeddingModel != "" && mem.EmbeddingModel != "" && mem.EmbeddingModel != l.config.EmbeddingModel {
+		return false
+	}
+	return true
+}
+
+// Save persists a new memory. If mem has no embedding, one is generated from
+// Content with the configured embedding function. The embedding dimension is
+// always recorded, and an untagged embedding is attributed to
+// LTMConfig.EmbeddingModel. A memory saved through a namespaced LTM (or ctx)
+// is stamped with that namespace. With LTMConfig.Redactor set, PII is removed
+// from Content before anything else sees it.
+func (l *LTM[ID]) Save(ctx context.Context, mem *Memory[ID]) error {
+	ctx, ns, err := l.resolveNamespace(ctx, mem.Namespace)
+	if err != nil {
+		return err
+	}
+	mem.Namespace = ns
+	if l.config.Redactor != nil {
+		if err := redactMemory(ctx, l.config.Redactor, mem); err != nil {
+			return err
+		}
+	}
+	if mem.CreatedAt.IsZero() {
+		mem.CreatedAt = l.now()
+	}
+	if len(mem.Embedding) == 0 && l.embedding != nil {
+		emb, err := l.embedding(ctx, mem.Content)
+		if err != nil {
+			return fmt.Errorf("embedding generation failed: %w", err)
+		}
+		mem.Embedding = emb
+	}
+	if len(mem.Entities) == 0 && l.config.EntityExtractor != nil {
+		mem.Entities = l.config.EntityExtractor.ExtractEntities(mem.Content)
+	}
+	if len(mem.Embedding) > 0 {
+		mem.EmbeddingDim = len(mem.Embedding)
+		if mem.EmbeddingModel == "" {
+			mem.EmbeddingModel = l.config.EmbeddingModel
+		}
+	}
+	if err := l.estimateImportance(ctx, ns, mem); err != nil {
+		return err
+	}
+	if err := l.store.SaveMemory(ctx, mem); err != nil {
+		return fmt.Errorf("memory store error: %w", err)
+	}
+	return l.autoLink(ctx, ns, mem)
 }
 
 // dateDelta returns the ranking adjustment for the date factor. It is zero
@@ -142,8 +374,16 @@ func (l *LTM[ID]) dateDelta(q SearchQuery, mem Memory[ID]) float64 {
 	return l.config.DatePenalty
 }
 
-// ApplyFeedback adjusts the boost value for the given memories.
+// ApplyFeedback adjusts the boost value for the given memories. When the LTM
+// or ctx is bound to a namespace, every ID must belong to it.
 func (l *LTM[ID]) ApplyFeedback(ctx context.Context, memoryIDs []ID, delta float64) error {
+	ctx, ns, err := l.resolveNamespace(ctx, Namespace{})
+	if err != nil {
+		return err
+	}
+	if err := l.checkOwned(ctx, ns, memoryIDs); err != nil {
+		return err
+	}
 	for _, id := range memoryIDs {
 		if err := l.store.UpdateBoost(ctx, id, delta); err != nil {
 			return err
@@ -152,6 +392,28 @@ func (l *LTM[ID]) ApplyFeedback(ctx context.Context, memoryIDs []ID, delta float
 	return nil
 }
 
+// Delete removes a memory. When the store implements SoftDeleteStore the
+// memory is only tombstoned: Search stops returning it at once, Restore
+// brings it back and Purge removes it for good. Otherwise it is removed
+// immediately, along with its graph links. When the LTM or ctx is bound to a
+// namespace, the memory must belong to it.
+func (l *LTM[ID]) Delete(ctx context.Context, id ID) error {
+	ctx, ns, err := l.resolveNamespace(ctx, Namespace{})
+	if err != nil {
+		return err
+	}
+	if err := l.checkOwned(ctx, ns, []ID{id}); err != nil {
+		return err
+	}
+	if l.softDelete != nil {
+		if err := l.softDelete.SetDeleted(ctx, id, l.now()); err != nil {
+			return fmt.Errorf("memory store error: %w", err)
+		}
+		return nil
+	}
+	return l.hardDelete(ctx, id)
+}
+
 // dateLayouts are the accepted date formats, tried in order. Both zero-padded
 // and non-padded numeric forms are accepted, along with RFC3339 timestamps and
 // year-month-only values.
@@ -198,17 +460,31 @@ func CosineSimilarity(a, b []float64) float64 {
 	if len(a) != len(b) || len(a) == 0 {
 		return 0
 	}
+	return cosineWithNorm(a, b, vectorNorm(a))
+}
 
-	var dot, normA, normB float64
+// cosineWithNorm computes the cosine similarity of equal-length vectors when
+// the norm of a is already known, as it is for a query scored against many
+// memories. The norm of b is accumulated in the same pass as the dot
+// product.
+func cosineWithNorm(a, b []float64, normA float64) float64 {
+	var dot, sumB float64
 	for i := range a {
 		dot += a[i] * b[i]
-		normA += a[i] * a[i]
-		normB += b[i] * b[i]
+		sumB += b[i] * b[i]
 	}
-
-	denom := math.Sqrt(normA) * math.Sqrt(normB)
+	denom := normA * math.Sqrt(sumB)
 	if denom == 0 {
 		return 0
 	}
 	return dot / denom
 }
+
+// vectorNorm returns the Euclidean norm of v.
+func vectorNorm(v []float64) float64 {
+	var sum float64
+	for _, x := range v {
+		sum += x * x
+	}
+	return math.Sqrt(sum)
+}
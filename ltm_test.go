@@ -2,6 +2,7 @@ package memai
 
 import (
 	"context"
+	"errors"
 	"math"
 	"testing"
 )
@@ -18,7 +19,15 @@ func (m *mockStore) SaveMemory(_ context.Context, mem *Memory[int]) error {
 	m.memories = append(m.memories, *mem)
 	return nil
 }
-func (m *mockStore) DeleteMemory(_ context.Context, id int) error { return nil }
+func (m *mockStore) DeleteMemory(_ context.Context, id int) error {
+	for i := range m.memories {
+		if m.memories[i].ID == id {
+			m.memories = append(m.memories[:i], m.memories[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
 func (m *mockStore) UpdateBoost(_ context.Context, id int, delta float64) error {
 	for i := range m.memories {
 		if m.memories[i].ID == id {
@@ -28,6 +37,17 @@ func (m *mockStore) UpdateBoost(_ context.Context, id int, delta float64) error
 	return nil
 }
 
+func (m *mockStore) UpdateEmbedding(_ context.Context, id int, emb []float64, model string) error {
+	for i := range m.memories {
+		if m.memories[i].ID == id {
+			m.memories[i].Embedding = emb
+			m.memories[i].EmbeddingModel = model
+			m.memories[i].EmbeddingDim = len(emb)
+		}
+	}
+	return nil
+}
+
 func TestCosineSimilarity_Identical(t *testing.T) {
 	v := []float64{1, 0, 0}
 	sim := CosineSimilarity(v, v)
@@ -191,3 +211,44 @@ func TestDateMatches_Normalization(t *testing.T) {
 		}
 	}
 }
+
+// Regression: memories embedded by another model or with another dimension
+// must be skipped (or reported), never scored as dissimilar.
+func TestLTM_IncompatibleEmbeddings(t *testing.T) {
+	store := &mockStore{
+		memories: []Memory[int]{
+			{ID: 1, Content: "current", Embedding: []float64{1, 0, 0}, EmbeddingModel: "m2"},
+			{ID: 2, Content: "old-model", Embedding: []float64{1, 0, 0}, EmbeddingModel: "m1"},
+			{ID: 3, Content: "old-dim", Embedding: []float64{1, 0}},
+		},
+	}
+	cfg := DefaultLTMConfig()
+	cfg.EmbeddingModel = "m2"
+	results, err := NewLTM(store, nil, cfg).Search(context.Background(), SearchQuery{QueryEmbedding: []float64{1, 0, 0}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].Memory.ID != 1 {
+		t.Fatalf("expected only the compatible memory, got %+v", results)
+	}
+
+	cfg.Incompatible = IncompatibleError
+	_, err = NewLTM(store, nil, cfg).Search(context.Background(), SearchQuery{QueryEmbedding: []float64{1, 0, 0}})
+	if !errors.Is(err, ErrIncompatibleEmbedding) {
+		t.Errorf("expected ErrIncompatibleEmbedding, got %v", err)
+	}
+}
+
+func TestLTM_SaveRecordsEmbeddingModel(t *testing.T) {
+	store := &mockStore{}
+	cfg := DefaultLTMConfig()
+	cfg.EmbeddingModel = "m1"
+	embed := func(_ context.Context, _ string) ([]float64, error) { return []float64{1, 0, 0, 0}, nil }
+	if err := NewLTM(store, embed, cfg).Save(context.Background(), &Memory[int]{ID: 1, Content: "x"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := store.memories[0]
+	if got.EmbeddingModel != "m1" || got.EmbeddingDim != 4 {
+		t.Errorf("expected model m1 / dim 4, got %q / %d", got.EmbeddingModel, got.EmbeddingDim)
+	}
+}
@@ -0,0 +1,333 @@
+package memai
+
+import (
+	"strings"
+	"unicode"
+)
+
+// KeywordMatcher decides whether a message mentions any of a working memory
+// item's keywords. STM uses it to refresh items and mark them emotional.
+// Implement this interface for language-specific or fuzzy matching.
+type KeywordMatcher interface {
+	MatchKeywords(message string, keywords []string) bool
+}
+
+// containsMatcher is the default KeywordMatcher: a case-insensitive
+// substring test.
+type containsMatcher struct{}
+
+func (containsMatcher) MatchKeywords(message string, keywords []string) bool {
+	lower := strings.ToLower(message)
+	for _, kw := range keywords {
+		if kw != "" && strings.Contains(lower, strings.ToLower(kw)) {
+			return true
+		}
+	}
+	return false
+}
+
+// JapaneseMatcher implements KeywordMatcher for Japanese conversation.
+//
+// Message and keywords are compared after NormalizeJapanese, so コーヒー
+// matches こーひー and ｺｰﾋｰ. A keyword must not be followed by a rune of the
+// same script as its last rune, because Japanese compounds put the head
+// last: 会議 matches 定例会議 but not 会議室, and データ does not match
+// データベース. Verbs and i-adjectives in dictionary form match their
+// inflections: 行く matches 行きました and 行った, 食べる matches 食べたい,
+// 高い matches 高かった. 勉強する matches 勉強 on its own. A stem directly after
+// another kanji is taken as part of a compound, so 行く does not match 銀行に,
+// unless the kanji before it form a time word or other stopword: 行く
+// matches 明日行く and 来週行きます.
+type JapaneseMatcher struct{}
+
+// NewJapaneseMatcher returns the built-in Japanese matcher.
+func NewJapaneseMatcher() *JapaneseMatcher {
+	return &JapaneseMatcher{}
+}
+
+// MatchKeywords implements KeywordMatcher.
+func (m *JapaneseMatcher) MatchKeywords(message string, keywords []string) bool {
+	msg := normalizeJA(message)
+	for _, kw := range keywords {
+		if k := jaKeyword(kw); len(k.text) > 0 && k.matches(msg) {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeJapanese folds the spelling variants of Japanese text: full-width
+// ASCII and the ideographic space become ASCII, half-width katakana becomes
+// full-width, katakana becomes hiragana, a long vowel mark becomes the vowel
+// it extends (コーヒー and こおひい fold alike), wave dashes are dropped, and
+// Latin letters are lowercased.
+func NormalizeJapanese(s string) string {
+	return string(normalizeJA(s).text)
+}
+
+// jaText is normalized text with the script of each rune before folding,
+// used for compound boundaries.
+type jaText struct {
+	text    []rune
+	classes []runeClass
+}
+
+// halfWidthKana maps U+FF61..U+FF9D to their full-width forms.
+var halfWidthKana = []rune("。「」、・ヲァィゥェォャュョッーアイウエオカキクケコサシスセソタチツテトナニヌネノハヒフヘホマミムメモヤユヨラリルレロワン")
+
+func normalizeJA(s string) jaText {
+	var out jaText
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r >= 0xFF01 && r <= 0xFF5E:
+			r -= 0xFEE0
+		case r == '　':
+			r = ' '
+		case r >= 0xFF61 && r <= 0xFF9D:
+			r = halfWidthKana[r-0xFF61]
+			if i+1 < len(runes) {
+				switch runes[i+1] {
+				case 'ﾞ':
+					if v, ok := voiced(r); ok {
+						r = v
+						i++
+					}
+				case 'ﾟ':
+					if r >= 'ハ' && r <= 'ホ' && (r-'ハ')%3 == 0 {
+						r += 2
+						i++
+					}
+				}
+			}
+		}
+		class := classOf(r)
+		switch {
+		case r == '〜' || r == '～':
+			continue
+		case r == 'ー':
+			v := longVowel(out.text)
+			if v == 0 {
+				continue
+			}
+			r = v
+		case r >= 'ァ' && r <= 'ヶ':
+			r -= 0x60
+		default:
+			r = unicode.ToLower(r)
+		}
+		out.text = append(out.text, r)
+		out.classes = append(out.classes, class)
+	}
+	return out
+}
+
+// vowelRows lists hiragana by the vowel they end in.
+var vowelRows = [...]struct {
+	vowel rune
+	kana  string
+}{
+	{'あ', "あかがさざただなはばぱまやらわぁゃゎ"},
+	{'い', "いきぎしじちぢにひびぴみりぃ"},
+	{'う', "うくぐすずつづぬふぶぷむゆるぅゅゔ"},
+	{'え', "えけげせぜてでねへべぺめれぇ"},
+	{'お', "おこごそぞとどのほぼぽもよろをぉょ"},
+}
+
+// longVowel returns the vowel a long vowel mark extends, from the last rune
+// of the folded text so far, or 0 if there is none.
+func longVowel(text []rune) rune {
+	if len(text) == 0 {
+		return 0
+	}
+	prev := text[len(text)-1]
+	for _, row := range vowelRows {
+		if strings.ContainsRune(row.kana, prev) {
+			return row.vowel
+		}
+	}
+	return 0
+}
+
+// voiced returns the dakuten form of a katakana rune.
+func voiced(r rune) (rune, bool) {
+	switch {
+	case r == 'ウ':
+		return 'ヴ', true
+	case r >= 'カ' && r <= 'ヂ' && (r-'カ')%2 == 0:
+		return r + 1, true
+	case r >= 'ツ' && r <= 'ド' && (r-'ツ')%2 == 0:
+		return r + 1, true
+	case r >= 'ハ' && r <= 'ホ' && (r-'ハ')%3 == 0:
+		return r + 1, true
+	}
+	return r, false
+}
+
+// jaKeywordForm is a normalized keyword and how its matches are checked.
+type jaKeywordForm struct {
+	jaText
+	// inflected: the keyword was reduced to its stem and must be followed
+	// by hiragana (an inflectional ending).
+	inflected bool
+}
+
+// dictionaryEndings are the final kana of verbs and i-adjectives in
+// dictionary form.
+const dictionaryEndings = "うくぐすつぬぶむるい"
+
+// jaKeyword normalizes kw and reduces verbs and i-adjectives written with
+// kanji to their stem.
+func jaKeyword(kw string) jaKeywordForm {
+	runes := []rune(strings.TrimSpace(kw))
+	n := len(runes)
+	if n >= 3 && string(runes[n-2:]) == "する" && classOf(runes[n-3]) == classKanji {
+		return jaKeywordForm{jaText: normalizeJA(string(runes[:n-2]))}
+	}
+	if n >= 2 && strings.ContainsRune(dictionaryEndings, runes[n-1]) && hasKanji(runes[:n-1]) {
+		return jaKeywordForm{jaText: normalizeJA(string(runes[:n-1])), inflected: true}
+	}
+	return jaKeywordForm{jaText: normalizeJA(kw)}
+}
+
+func hasKanji(runes []rune) bool {
+	for _, r := range runes {
+		if classOf(r) == classKanji {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether k occurs in msg at a word boundary.
+func (k jaKeywordForm) matches(msg jaText) bool {
+	n := len(k.text)
+	last := k.classes[n-1]
+	for i := 0; i+n <= len(msg.text); i++ {
+		if !runesEqual(msg.text[i:i+n], k.text) {
+			continue
+		}
+		if i > 0 && (last == classLatin && msg.classes[i-1] == classLatin ||
+			k.inflected && k.classes[0] == classKanji && msg.classes[i-1] == classKanji && !afterStopword(msg, i)) {
+			// Inside a word (art in start), or a verb stem inside a
+			// compound (行 in 銀行).
+			continue
+		}
+		if i+n == len(msg.text) {
+			if k.inflected {
+				continue
+			}
+			return true
+		}
+		next := msg.classes[i+n]
+		if k.inflected {
+			if next == classHiragana {
+				return true
+			}
+			continue
+		}
+		if next == last && last != classHiragana && last != classOther {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// afterStopword reports whether the kanji run ending just before msg.text[i]
+// is a word like 明日 or 来週 that does not form compounds with a verb.
+func afterStopword(msg jaText, i int) bool {
+	j := i
+	for j > 0 && msg.classes[j-1] == classKanji {
+		j--
+	}
+	return topicStopwordsJA[string(msg.text[j:i])]
+}
+
+func runesEqual(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// EnglishMatcher implements KeywordMatcher for English conversation by
+// comparing whole word tokens, so "art" does not match "start". With Stem
+// set, tokens are compared by their StemEnglish stems, so "meeting" matches
+// "meetings" and "met" does not. Multi-word keywords ("cherry blossom")
+// match the same words in sequence.
+type EnglishMatcher struct {
+	Stem bool // Compare Porter stems instead of exact tokens
+}
+
+// NewEnglishMatcher returns an EnglishMatcher, stemming if stem is true.
+func NewEnglishMatcher(stem bool) *EnglishMatcher {
+	return &EnglishMatcher{Stem: stem}
+}
+
+// MatchKeywords implements KeywordMatcher.
+func (m *EnglishMatcher) MatchKeywords(message string, keywords []string) bool {
+	msg := m.tokens(message)
+	for _, kw := range keywords {
+		if k := m.tokens(kw); len(k) > 0 && containsSequence(msg, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// tokens splits s into lowercase word tokens, stemmed if m.Stem is set.
+// Possessive 's is dropped.
+func (m *EnglishMatcher) tokens(s string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\'' && r != '’'
+	})
+	out := fields[:0]
+	for _, f := range fields {
+		f = strings.Trim(f, "'’")
+		f = strings.TrimSuffix(strings.TrimSuffix(f, "'s"), "’s")
+		if f == "" {
+			continue
+		}
+		if m.Stem {
+			f = StemEnglish(f)
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// containsSequence reports whether seq occurs in tokens as consecutive
+// elements.
+func containsSequence(tokens, seq []string) bool {
+	for i := 0; i+len(seq) <= len(tokens); i++ {
+		match := true
+		for j, s := range seq {
+			if tokens[i+j] != s {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// matcherFor returns the KeywordMatcher STM uses for config: KeywordMatcher
+// if set, else the matcher of Language, else substring matching.
+func matcherFor(config STMConfig) KeywordMatcher {
+	switch {
+	case config.KeywordMatcher != nil:
+		return config.KeywordMatcher
+	case config.Language == LangEnglish:
+		return NewEnglishMatcher(true)
+	case config.Language == LangJapanese:
+		return NewJapaneseMatcher()
+	}
+	return containsMatcher{}
+}
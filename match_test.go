@@ -0,0 +1,130 @@
+package memai
+
+import "testing"
+
+func TestNormalizeJapanese(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"コーヒー", "こおひい"},
+		{"ｺｰﾋｰ", "こおひい"},
+		{"ｶﾞｲﾄﾞﾌﾞｯｸ", "がいどぶっく"},
+		{"ﾊﾟﾝ", "ぱん"},
+		{"ＡＷＳ　設定", "aws 設定"},
+		{"すご〜い", "すごい"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeJapanese(tt.in); got != tt.want {
+			t.Errorf("NormalizeJapanese(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestJapaneseMatcher(t *testing.T) {
+	m := NewJapaneseMatcher()
+	tests := []struct {
+		keyword, message string
+		want             bool
+	}{
+		{"会議", "明日の会議に出る", true},
+		{"会議", "定例会議が長い", true},
+		{"会議", "会議室を予約した", false},
+		{"データ", "データベースが落ちた", false},
+		{"データ", "でーたを送った", true},
+		{"コーヒー", "こーひー飲みたい", true},
+		{"コーヒー", "ｺｰﾋｰ飲みたい", true},
+		{"行く", "京都に行きました", true},
+		{"行く", "昨日、行った店", true},
+		{"行く", "銀行に寄った", false},
+		{"行く", "明日行く", true},
+		{"行く", "来週行きます", true},
+		{"行く", "昨日行った店", true},
+		{"行く", "急行に乗った", false},
+		{"行く", "行動する", false},
+		{"食べる", "ラーメン食べたい", true},
+		{"食べる", "食べ物の話", false},
+		{"高い", "ホテルが高かった", true},
+		{"勉強する", "英語の勉強が進まない", true},
+		{"AWS", "ＡＷＳの設定", true},
+		{"art", "start the car", false},
+	}
+	for _, tt := range tests {
+		if got := m.MatchKeywords(tt.message, []string{tt.keyword}); got != tt.want {
+			t.Errorf("MatchKeywords(%q, %q) = %v, want %v", tt.message, tt.keyword, got, tt.want)
+		}
+	}
+}
+
+func TestSTM_KeywordMatcher(t *testing.T) {
+	items := func() []*WorkingMemoryItem {
+		return []*WorkingMemoryItem{{Topic: "会議", Keywords: []string{"会議"}, Activation: 0.5}}
+	}
+
+	def := NewSTM(DefaultSTMConfig())
+	def.SetItems(items())
+	def.Update(0, "会議室が取れない", nil)
+	if def.Items()[0].Activation <= 0.5 {
+		t.Error("default substring matcher should refresh on 会議室")
+	}
+
+	config := DefaultSTMConfig()
+	config.KeywordMatcher = NewJapaneseMatcher()
+	ja := NewSTM(config)
+	ja.SetItems(items())
+	ja.Update(0, "会議室が取れない", &EmotionalState{Primary: EmotionAnger, Intensity: 0.8})
+	if got := ja.Items()[0]; got.Activation != 0.5 || got.Emotional {
+		t.Errorf("Japanese matcher should not refresh or mark on 会議室: %+v", got)
+	}
+	ja.Update(0, "定例会議、本当に疲れた", &EmotionalState{Primary: EmotionSadness, Intensity: 0.8})
+	if got := ja.Items()[0]; got.Activation <= 0.5 || !got.Emotional {
+		t.Errorf("Japanese matcher should refresh and mark on 定例会議: %+v", got)
+	}
+}
+
+func TestEnglishMatcher(t *testing.T) {
+	exact := NewEnglishMatcher(false)
+	stem := NewEnglishMatcher(true)
+	tests := []struct {
+		m                *EnglishMatcher
+		keyword, message string
+		want             bool
+	}{
+		{exact, "art", "let's start", false},
+		{exact, "art", "modern art is fun", true},
+		{exact, "Art", "ART museum", true},
+		{exact, "meeting", "two meetings today", false},
+		{stem, "meeting", "two meetings today", true},
+		{stem, "plan", "we're planning dinner", true},
+		{exact, "cherry blossom", "the cherry blossom festival", true},
+		{exact, "cherry blossom", "cherry pie and blossom", false},
+		{stem, "dinner plan", "any dinner plans?", true},
+		{exact, "sister", "my sister's wedding", true},
+	}
+	for _, tt := range tests {
+		if got := tt.m.MatchKeywords(tt.message, []string{tt.keyword}); got != tt.want {
+			t.Errorf("MatchKeywords(%q, %q) stem=%v = %v, want %v", tt.message, tt.keyword, tt.m.Stem, got, tt.want)
+		}
+	}
+}
+
+func TestSTM_LanguageSelectsMatcher(t *testing.T) {
+	config := DefaultSTMConfig()
+	config.Language = LangEnglish
+	stm := NewSTM(config)
+	stm.SetItems([]*WorkingMemoryItem{
+		{Topic: "art", Keywords: []string{"art"}, Activation: 0.5},
+		{Topic: "meeting", Keywords: []string{"meeting"}, Activation: 0.5},
+	})
+	stm.Update(0, "Let's start the meetings", &EmotionalState{Primary: EmotionJoy, Intensity: 0.8})
+
+	for _, it := range stm.Items() {
+		switch it.Topic {
+		case "art":
+			if it.Activation != 0.5 || it.Emotional {
+				t.Errorf("art should not match start: %+v", it)
+			}
+		case "meeting":
+			if it.Activation <= 0.5 || !it.Emotional {
+				t.Errorf("meeting should match meetings: %+v", it)
+			}
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package memai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Namespace scopes memories to a tenant, an end user and an agent. The zero
+// Namespace means "unscoped": an LTM without a namespace sees every memory,
+// as it did before namespaces existed.
+type Namespace struct {
+	Tenant string
+	User   string
+	Agent  string
+}
+
+// IsZero reports whether n is the unscoped namespace.
+func (n Namespace) IsZero() bool {
+	return n == Namespace{}
+}
+
+// String returns n as "tenant/user/agent", suitable as a storage key.
+func (n Namespace) String() string {
+	return n.Tenant + "/" + n.User + "/" + n.Agent
+}
+
+// ErrNamespaceMismatch is returned when an operation names a namespace other
+// than the one the LTM, context or memory is bound to, or targets a memory
+// outside its namespace.
+var ErrNamespaceMismatch = errors.New("memai: namespace mismatch")
+
+type namespaceKey struct{}
+
+// WithNamespace returns a context carrying ns. LTM attaches the effective
+// namespace to the context of every store call, so a namespace-aware
+// MemoryStore can read it with NamespaceFromContext and only touch that
+// namespace's rows.
+func WithNamespace(ctx context.Context, ns Namespace) context.Context {
+	return context.WithValue(ctx, namespaceKey{}, ns)
+}
+
+// NamespaceFromContext returns the namespace carried by ctx, if any.
+func NamespaceFromContext(ctx context.Context) (Namespace, bool) {
+	ns, ok := ctx.Value(namespaceKey{}).(Namespace)
+	return ns, ok && !ns.IsZero()
+}
+
+// resolveNamespace merges the namespaces bound to the LTM, carried by ctx and
+// given explicitly (e.g. SearchQuery.Namespace). Any non-zero values must
+// agree. The returned context carries the effective namespace.
+func (l *LTM[ID]) resolveNamespace(ctx context.Context, explicit Namespace) (context.Context, Namespace, error) {
+	ns := l.namespace
+	fromCtx, _ := NamespaceFromContext(ctx)
+	for _, n := range []Namespace{fromCtx, explicit} {
+		if n.IsZero() {
+			continue
+		}
+		if !ns.IsZero() && n != ns {
+			return ctx, Namespace{}, ErrNamespaceMismatch
+		}
+		ns = n
+	}
+	if ns.IsZero() {
+		return ctx, ns, nil
+	}
+	return WithNamespace(ctx, ns), ns, nil
+}
+
+// memories fetches the live memories visible in ns. The store is trusted to
+// scope by the context namespace, but results are filtered again here so a
+// store that ignores namespaces can never leak another tenant's memories.
+// Soft-deleted memories are left out.
+func (l *LTM[ID]) memories(ctx context.Context, ns Namespace) ([]Memory[ID], error) {
+	return l.scopedMemories(ctx, ns, false)
+}
+
+// scopedMemories fetches the memories visible in ns, including soft-deleted
+// ones when deleted is set.
+func (l *LTM[ID]) scopedMemories(ctx context.Context, ns Namespace, deleted bool) ([]Memory[ID], error) {
+	memories, err := l.store.GetMemories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return scopeMemories(memories, ns, deleted), nil
+}
+
+// scopeMemories returns the memories in ns (all of them for the unscoped
+// namespace), leaving out soft-deleted ones unless deleted is set.
+func scopeMemories[ID comparable](memories []Memory[ID], ns Namespace, deleted bool) []Memory[ID] {
+	keep := func(mem Memory[ID]) bool {
+		return (ns.IsZero() || mem.Namespace == ns) && (deleted || mem.DeletedAt.IsZero())
+	}
+	for i, mem := range memories {
+		if keep(mem) {
+			continue
+		}
+		// Copy on the first memory to drop, so the common case of nothing
+		// to filter does not allocate.
+		visible := slices.Clone(memories[:i])
+		for _, mem := range memories[i+1:] {
+			if keep(mem) {
+				visible = append(visible, mem)
+			}
+		}
+		return visible
+	}
+	return memories
+}
+
+// checkOwned verifies that every id belongs to ns before a mutation is
+// forwarded to the store. It is a no-op for the unscoped namespace. Stores
+// implementing NamespaceLookup are asked about ids only; others are scanned.
+func (l *LTM[ID]) checkOwned(ctx context.Context, ns Namespace, ids []ID) error {
+	if ns.IsZero() {
+		return nil
+	}
+	owners, err := l.owners(ctx, ns, ids)
+	if err != nil {
+		return fmt.Errorf("memory store error: %w", err)
+	}
+	for _, id := range ids {
+		if owner, ok := owners[id]; !ok || owner != ns {
+			return fmt.Errorf("%w: memory %v is not in %s", ErrNamespaceMismatch, id, ns)
+		}
+	}
+	return nil
+}
+
+// owners returns the namespace of the memories in ids that exist, or at
+// least of those in ns.
+func (l *LTM[ID]) owners(ctx context.Context, ns Namespace, ids []ID) (map[ID]Namespace, error) {
+	if l.lookup != nil {
+		return l.lookup.LookupNamespaces(ctx, ids)
+	}
+	memories, err := l.scopedMemories(ctx, ns, true)
+	if err != nil {
+		return nil, err
+	}
+	owners := make(map[ID]Namespace, len(memories))
+	for _, mem := range memories {
+		owners[mem.ID] = mem.Namespace
+	}
+	return owners, nil
+}
+
+// NamespaceManager hands out per-namespace LTM views over a shared store, so
+// one process can serve many end users without wiring a store per user.
+// Views are safe for concurrent use. Each namespace's view is cached for the
+// life of the manager; a view is a small struct sharing the store and
+// background writer, so the cache costs a few hundred bytes per namespace.
+// When namespaces are unbounded, e.g. one per anonymous session, scope calls
+// on a single LTM with WithNamespace instead.
+type NamespaceManager[ID comparable] struct {
+	mu    sync.Mutex
+	root  *LTM[ID]
+	views map[Namespace]*LTM[ID]
+}
+
+// NewNamespaceManager creates a manager whose views share store, embeddingFn
+// and config.
+func NewNamespaceManager[ID comparable](store MemoryStore[ID], embeddingFn EmbeddingFunc, config LTMConfig) *NamespaceManager[ID] {
+	return &NamespaceManager[ID]{
+		root:  NewLTM(store, embeddingFn, config),
+		views: make(map[Namespace]*LTM[ID]),
+	}
+}
+
+// LTM returns the view bound to ns. Every operation on the view is confined
+// to ns: Search only returns its memories, Save stamps it on new memories,
+// and ApplyFeedback and Delete reject IDs from other namespaces.
+func (m *NamespaceManager[ID]) LTM(ns Namespace) *LTM[ID] {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if v, ok := m.views[ns]; ok {
+		return v
+	}
+	v := m.root.view(ns)
+	m.views[ns] = v
+	return v
+}
+
+// Close releases resources shared by all views (see LTM.Close).
+func (m *NamespaceManager[ID]) Close() error {
+	return m.root.Close()
+}
@@ -0,0 +1,129 @@
+package memai
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+var (
+	nsAlice = Namespace{Tenant: "acme", User: "alice"}
+	nsBob   = Namespace{Tenant: "acme", User: "bob"}
+)
+
+func TestNamespace_SearchIsolation(t *testing.T) {
+	store := &mockStore{}
+	mgr := NewNamespaceManager[int](store, nil, DefaultLTMConfig())
+	ctx := context.Background()
+
+	if err := mgr.LTM(nsAlice).Save(ctx, &Memory[int]{ID: 1, Content: "alice", Embedding: []float64{1, 0}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mgr.LTM(nsBob).Save(ctx, &Memory[int]{ID: 2, Content: "bob", Embedding: []float64{1, 0}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	results, err := mgr.LTM(nsAlice).Search(ctx, SearchQuery{QueryEmbedding: []float64{1, 0}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].Memory.Content != "alice" {
+		t.Errorf("alice's view leaked or lost memories: %+v", results)
+	}
+
+	// A query naming another namespace is rejected rather than widened.
+	_, err = mgr.LTM(nsAlice).Search(ctx, SearchQuery{QueryEmbedding: []float64{1, 0}, Namespace: nsBob})
+	if !errors.Is(err, ErrNamespaceMismatch) {
+		t.Errorf("expected ErrNamespaceMismatch, got %v", err)
+	}
+}
+
+func TestNamespace_MutationsConfined(t *testing.T) {
+	store := &mockStore{
+		memories: []Memory[int]{
+			{ID: 1, Content: "alice", Embedding: []float64{1, 0}, Namespace: nsAlice},
+			{ID: 2, Content: "bob", Embedding: []float64{1, 0}, Namespace: nsBob},
+		},
+	}
+	alice := NewNamespaceManager[int](store, nil, DefaultLTMConfig()).LTM(nsAlice)
+	ctx := context.Background()
+
+	if err := alice.ApplyFeedback(ctx, []int{2}, 1); !errors.Is(err, ErrNamespaceMismatch) {
+		t.Errorf("feedback on another namespace's memory: expected ErrNamespaceMismatch, got %v", err)
+	}
+	if err := alice.Delete(ctx, 2); !errors.Is(err, ErrNamespaceMismatch) {
+		t.Errorf("delete of another namespace's memory: expected ErrNamespaceMismatch, got %v", err)
+	}
+	if len(store.memories) != 2 || store.memories[1].Boost != 0 {
+		t.Error("bob's memory must be untouched")
+	}
+	if err := alice.Delete(ctx, 1); err != nil {
+		t.Errorf("unexpected error deleting own memory: %v", err)
+	}
+}
+
+// lookupStore is a mockStore that implements NamespaceLookup and counts full
+// scans.
+type lookupStore struct {
+	mockStore
+	scans int
+}
+
+func (s *lookupStore) GetMemories(ctx context.Context) ([]Memory[int], error) {
+	s.scans++
+	return s.mockStore.GetMemories(ctx)
+}
+
+func (s *lookupStore) LookupNamespaces(_ context.Context, ids []int) (map[int]Namespace, error) {
+	out := make(map[int]Namespace)
+	for _, mem := range s.memories {
+		for _, id := range ids {
+			if mem.ID == id {
+				out[id] = mem.Namespace
+			}
+		}
+	}
+	return out, nil
+}
+
+func TestNamespace_OwnershipLookup(t *testing.T) {
+	store := &lookupStore{mockStore: mockStore{
+		memories: []Memory[int]{
+			{ID: 1, Embedding: []float64{1, 0}, Namespace: nsAlice},
+			{ID: 2, Embedding: []float64{1, 0}, Namespace: nsBob},
+		},
+	}}
+	alice := NewNamespaceManager[int](store, nil, DefaultLTMConfig()).LTM(nsAlice)
+	ctx := context.Background()
+
+	if err := alice.ApplyFeedback(ctx, []int{1}, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := alice.ApplyFeedback(ctx, []int{2}, 1); !errors.Is(err, ErrNamespaceMismatch) {
+		t.Errorf("expected ErrNamespaceMismatch, got %v", err)
+	}
+	if err := alice.Delete(ctx, 3); !errors.Is(err, ErrNamespaceMismatch) {
+		t.Errorf("unknown ID: expected ErrNamespaceMismatch, got %v", err)
+	}
+	if store.scans != 0 {
+		t.Errorf("ownership checks should not scan the store, got %d scans", store.scans)
+	}
+}
+
+// An unscoped LTM honours a namespace carried by the context.
+func TestNamespace_FromContext(t *testing.T) {
+	store := &mockStore{
+		memories: []Memory[int]{
+			{ID: 1, Content: "alice", Embedding: []float64{1, 0}, Namespace: nsAlice},
+			{ID: 2, Content: "bob", Embedding: []float64{1, 0}, Namespace: nsBob},
+		},
+	}
+	ctx := WithNamespace(context.Background(), nsBob)
+	results, err := NewLTM(store, nil, DefaultLTMConfig()).Search(ctx, SearchQuery{QueryEmbedding: []float64{1, 0}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].Memory.ID != 2 {
+		t.Errorf("expected only bob's memory, got %+v", results)
+	}
+}
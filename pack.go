@@ -0,0 +1,259 @@
+package memai
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// TokenCounter estimates how many tokens a text costs in the target LLM.
+// Implement this interface to plug in the model's real tokenizer.
+type TokenCounter interface {
+	CountTokens(text string) int
+}
+
+// ApproxTokenCounter is a tokenizer-free TokenCounter for Japanese and
+// English. Each CJK character (kanji, kana, hangul, full-width forms) counts
+// as one token; other non-space characters count as a quarter token each,
+// the usual ratio for English text.
+type ApproxTokenCounter struct{}
+
+// CountTokens implements TokenCounter.
+func (ApproxTokenCounter) CountTokens(text string) int {
+	cjk, other := 0, 0
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			cjk++
+		case !unicode.IsSpace(r):
+			other++
+		}
+	}
+	return cjk + (other+3)/4
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) ||
+		(r >= 0xFF00 && r <= 0xFFEF) || (r >= 0x3000 && r <= 0x303F)
+}
+
+// ContextSource tells where a packed context item came from.
+type ContextSource string
+
+const (
+	SourceSTM ContextSource = "stm" // Working memory item
+	SourceLTM ContextSource = "ltm" // Long-term memory search result
+)
+
+// ContextOptions configures BuildContext.
+type ContextOptions struct {
+	Budget            int          // Token budget for all items (required)
+	Counter           TokenCounter // Token counter (default: ApproxTokenCounter)
+	STMWeight         float64      // Value multiplier for working memory items (default: 1.0)
+	LTMWeight         float64      // Value multiplier for long-term memories (default: 1.0)
+	MinTruncateTokens int          // Smallest truncated item worth including (default: 16)
+	DedupThreshold    float64      // Bigram overlap at which two items count as duplicates (default: 0.6)
+}
+
+// ContextItem is one piece of memory selected for the prompt.
+type ContextItem[ID comparable] struct {
+	Source    ContextSource
+	Text      string  // Possibly truncated content
+	Tokens    int     // Token cost of Text
+	Value     float64 // Weighted value used for selection
+	Truncated bool
+
+	WorkingMemory *WorkingMemoryItem // Set for SourceSTM (a copy)
+	Memory        *SearchResult[ID]  // Set for SourceLTM (a copy)
+}
+
+// PackedContext is the result of BuildContext.
+type PackedContext[ID comparable] struct {
+	Items   []ContextItem[ID] // Selected items, highest value first
+	Tokens  int               // Total tokens of Items
+	Budget  int
+	Dropped int // Candidates left out as duplicates or for lack of budget
+}
+
+// ellipsis marks truncated content.
+const ellipsis = "…"
+
+// BuildContext selects the most valuable working-memory items and long-term
+// memories that fit in opts.Budget tokens.
+//
+// Working memory items are valued by Activation and long-term memories by
+// Score relative to the best result, each scaled by its weight. Candidates
+// are taken in order of value; one whose text overlaps an already selected
+// item (the same fact in STM and LTM, say) is skipped. When the next
+// candidate does not fit, it is truncated at a sentence or word boundary if
+// at least MinTruncateTokens remain, otherwise skipped in favour of smaller
+// ones.
+func BuildContext[ID comparable](stm []*WorkingMemoryItem, ltm []SearchResult[ID], opts ContextOptions) PackedContext[ID] {
+	opts = opts.withDefaults()
+	var candidates []ContextItem[ID]
+	for _, it := range stm {
+		text := it.Content
+		if text == "" {
+			text = it.Topic
+		}
+		c := *it
+		candidates = append(candidates, ContextItem[ID]{
+			Source:        SourceSTM,
+			Text:          text,
+			Value:         it.Activation * opts.STMWeight,
+			WorkingMemory: &c,
+		})
+	}
+	best := 0.0
+	for _, r := range ltm {
+		best = max(best, r.Score)
+	}
+	for _, r := range ltm {
+		value := 0.0
+		if best > 0 {
+			value = r.Score / best * opts.LTMWeight
+		}
+		c := r
+		candidates = append(candidates, ContextItem[ID]{
+			Source: SourceLTM,
+			Text:   r.Memory.Content,
+			Value:  value,
+			Memory: &c,
+		})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Value > candidates[j].Value
+	})
+
+	packed := PackedContext[ID]{Budget: opts.Budget}
+	var chosen []map[string]struct{}
+	for _, c := range candidates {
+		grams := bigrams(c.Text)
+		if overlapsAny(grams, chosen, opts.DedupThreshold) {
+			packed.Dropped++
+			continue
+		}
+		remaining := opts.Budget - packed.Tokens
+		c.Tokens = opts.Counter.CountTokens(c.Text)
+		if c.Tokens > remaining {
+			if remaining < opts.MinTruncateTokens {
+				packed.Dropped++
+				continue
+			}
+			c.Text = truncateToTokens(c.Text, remaining, opts.Counter)
+			c.Tokens = opts.Counter.CountTokens(c.Text)
+			c.Truncated = true
+			if c.Text == "" || c.Tokens > remaining {
+				packed.Dropped++
+				continue
+			}
+		}
+		packed.Items = append(packed.Items, c)
+		packed.Tokens += c.Tokens
+		chosen = append(chosen, grams)
+	}
+	return packed
+}
+
+func (o ContextOptions) withDefaults() ContextOptions {
+	if o.Counter == nil {
+		o.Counter = ApproxTokenCounter{}
+	}
+	if o.STMWeight == 0 {
+		o.STMWeight = 1
+	}
+	if o.LTMWeight == 0 {
+		o.LTMWeight = 1
+	}
+	if o.MinTruncateTokens <= 0 {
+		o.MinTruncateTokens = 16
+	}
+	if o.DedupThreshold <= 0 {
+		o.DedupThreshold = 0.6
+	}
+	return o
+}
+
+// truncateToTokens shortens text so that it plus an ellipsis fits in budget
+// tokens. It cuts at the last sentence end, or failing that the last space,
+// in the final 30% of the kept text; otherwise at a rune boundary.
+func truncateToTokens(text string, budget int, counter TokenCounter) string {
+	runes := []rune(text)
+	// Binary search the longest prefix that fits.
+	lo, hi := 0, len(runes)
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if counter.CountTokens(string(runes[:mid])+ellipsis) <= budget {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	if lo == 0 {
+		return ""
+	}
+	cut := lo
+	floor := lo * 7 / 10
+	if i := lastIndexRune(runes[:lo], floor, func(r rune) bool { return strings.ContainsRune("。！？.!?\n", r) }); i >= 0 {
+		cut = i + 1
+	} else if i := lastIndexRune(runes[:lo], floor, unicode.IsSpace); i >= 0 {
+		cut = i
+	}
+	return strings.TrimSpace(string(runes[:cut])) + ellipsis
+}
+
+// lastIndexRune returns the index of the last rune at or after floor that
+// satisfies f, or -1.
+func lastIndexRune(runes []rune, floor int, f func(rune) bool) int {
+	for i := len(runes) - 1; i >= floor; i-- {
+		if f(runes[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// bigrams returns the set of character bigrams of text, lowercased and with
+// whitespace and punctuation removed. Bigrams work for both spaced and
+// unspaced scripts.
+func bigrams(text string) map[string]struct{} {
+	var runes []rune
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			runes = append(runes, r)
+		}
+	}
+	set := make(map[string]struct{}, len(runes))
+	if len(runes) == 1 {
+		set[string(runes)] = struct{}{}
+	}
+	for i := 0; i+1 < len(runes); i++ {
+		set[string(runes[i:i+2])] = struct{}{}
+	}
+	return set
+}
+
+// overlapsAny reports whether grams overlaps any chosen set by at least
+// threshold, measured as the share of the smaller set that is shared, so a
+// short STM topic contained in a longer memory counts as a duplicate.
+func overlapsAny(grams map[string]struct{}, chosen []map[string]struct{}, threshold float64) bool {
+	for _, other := range chosen {
+		small, large := grams, other
+		if len(small) > len(large) {
+			small, large = large, small
+		}
+		if len(small) == 0 {
+			continue
+		}
+		shared := 0
+		for g := range small {
+			if _, ok := large[g]; ok {
+				shared++
+			}
+		}
+		if float64(shared)/float64(len(small)) >= threshold {
+			return true
+		}
+	}
+	return false
+}
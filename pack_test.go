@@ -0,0 +1,64 @@
+package memai
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApproxTokenCounter(t *testing.T) {
+	c := ApproxTokenCounter{}
+	if got := c.CountTokens("明日の会議"); got != 5 {
+		t.Errorf("expected 5 tokens for 5 CJK characters, got %d", got)
+	}
+	if got := c.CountTokens("meeting tomorrow"); got != 4 {
+		t.Errorf("expected 4 tokens for 15 letters, got %d", got)
+	}
+}
+
+func TestBuildContext_BudgetAndDedup(t *testing.T) {
+	stm := []*WorkingMemoryItem{
+		{Topic: "会議", Content: "明日の会議は10時から", Activation: 0.9},
+	}
+	ltm := []SearchResult[int]{
+		{Memory: Memory[int]{ID: 1, Content: "明日の会議は10時から"}, Score: 1.0},
+		{Memory: Memory[int]{ID: 2, Content: "ランチは寿司"}, Score: 0.8},
+		{Memory: Memory[int]{ID: 3, Content: "旅行の計画"}, Score: 0.5},
+	}
+	packed := BuildContext(stm, ltm, ContextOptions{Budget: 18})
+
+	if packed.Tokens > 18 {
+		t.Errorf("budget exceeded: %d", packed.Tokens)
+	}
+	// The meeting appears in both STM and LTM; only the higher-valued copy
+	// (the LTM result) is kept, leaving room for the lunch memory.
+	if len(packed.Items) != 2 {
+		t.Fatalf("expected 2 items, got %+v", packed.Items)
+	}
+	for _, it := range packed.Items {
+		if it.Source == SourceSTM {
+			t.Error("STM duplicate of a higher-valued LTM memory should be dropped")
+		}
+	}
+	if packed.Items[1].Memory.Memory.ID != 2 {
+		t.Errorf("expected the lunch memory second, got %+v", packed.Items[1])
+	}
+	if packed.Dropped != 2 {
+		t.Errorf("expected 2 dropped candidates, got %d", packed.Dropped)
+	}
+}
+
+func TestBuildContext_Truncates(t *testing.T) {
+	long := strings.Repeat("We talked about the trip. ", 20)
+	ltm := []SearchResult[int]{{Memory: Memory[int]{ID: 1, Content: long}, Score: 1}}
+	packed := BuildContext[int](nil, ltm, ContextOptions{Budget: 30})
+	if len(packed.Items) != 1 {
+		t.Fatalf("expected 1 truncated item, got %d", len(packed.Items))
+	}
+	it := packed.Items[0]
+	if !it.Truncated || it.Tokens > 30 {
+		t.Errorf("expected truncated item within budget, got %d tokens", it.Tokens)
+	}
+	if !strings.HasSuffix(it.Text, "trip."+ellipsis) {
+		t.Errorf("expected a cut at a sentence boundary, got %q", it.Text)
+	}
+}
@@ -0,0 +1,94 @@
+package memai
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Citation describes where a result came from, in a form the agent can say
+// to the user, e.g. "you told me on 6/17 in thread trip". The date is the
+// provenance SourceTime, or CreatedAt when unknown; the thread is the
+// ConversationID, or ThreadKey. The year is left out for dates in the year
+// of SearchedAt (the current time if unset). lang is LangJapanese or
+// LangEnglish.
+func (r SearchResult[ID]) Citation(lang Language) string {
+	mem := r.Memory
+	p := mem.Provenance
+	when := p.SourceTime
+	if when.IsZero() {
+		when = mem.CreatedAt
+	}
+	date := ""
+	if !when.IsZero() {
+		now := r.SearchedAt
+		if now.IsZero() {
+			now = time.Now()
+		}
+		date = citationDate(when, now)
+	}
+	thread := p.ConversationID
+	if thread == "" {
+		thread = mem.ThreadKey
+	}
+
+	if lang == LangEnglish {
+		var b strings.Builder
+		switch p.Source {
+		case SourceUserMessage:
+			b.WriteString("you told me")
+		case SourceAgentMessage:
+			b.WriteString("I told you")
+		case SourceDocument:
+			b.WriteString("from a document")
+			if p.MessageID != "" {
+				fmt.Fprintf(&b, " (%s)", p.MessageID)
+			}
+		case SourceConsolidation:
+			b.WriteString("I concluded from earlier conversations")
+		default:
+			b.WriteString("noted")
+		}
+		if date != "" {
+			b.WriteString(" on " + date)
+		}
+		if thread != "" && p.Source != SourceConsolidation {
+			b.WriteString(" in thread " + thread)
+		}
+		return b.String()
+	}
+
+	var b strings.Builder
+	if date != "" {
+		b.WriteString(date + "に")
+	}
+	if thread != "" && p.Source != SourceConsolidation {
+		b.WriteString("スレッド" + thread + "で")
+	}
+	switch p.Source {
+	case SourceUserMessage:
+		b.WriteString("あなたが話してくれた")
+	case SourceAgentMessage:
+		b.WriteString("私が伝えた")
+	case SourceDocument:
+		b.WriteString("資料")
+		if p.MessageID != "" {
+			b.WriteString("「" + p.MessageID + "」")
+		}
+		b.WriteString("で読んだ")
+	case SourceConsolidation:
+		b.WriteString("これまでの会話から私がまとめた")
+	default:
+		b.WriteString("記録した")
+	}
+	return b.String()
+}
+
+// citationDate formats t as month/day, with the year when it is not the
+// year of now.
+func citationDate(t, now time.Time) string {
+	if t.Year() == now.Year() {
+		return t.Format("1/2")
+	}
+	return t.Format("2006/1/2")
+}
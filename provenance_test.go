@@ -0,0 +1,97 @@
+package memai
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSearchResult_Citation(t *testing.T) {
+	searched := time.Date(2026, 7, 1, 9, 0, 0, 0, time.UTC)
+	told := time.Date(2026, 6, 17, 20, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		mem  Memory[int]
+		lang Language
+		want string
+	}{
+		{
+			name: "user message en",
+			mem:  Memory[int]{Provenance: Provenance{Source: SourceUserMessage, ConversationID: "trip", SourceTime: told}},
+			lang: LangEnglish,
+			want: "you told me on 6/17 in thread trip",
+		},
+		{
+			name: "user message ja",
+			mem:  Memory[int]{Provenance: Provenance{Source: SourceUserMessage, ConversationID: "trip", SourceTime: told}},
+			lang: LangJapanese,
+			want: "6/17にスレッドtripであなたが話してくれた",
+		},
+		{
+			name: "falls back to CreatedAt and ThreadKey",
+			mem:  Memory[int]{ThreadKey: "work", CreatedAt: time.Date(2020, 1, 5, 0, 0, 0, 0, time.UTC), Provenance: Provenance{Source: SourceAgentMessage}},
+			lang: LangEnglish,
+			want: "I told you on 2020/1/5 in thread work",
+		},
+		{
+			name: "document",
+			mem:  Memory[int]{Provenance: Provenance{Source: SourceDocument, MessageID: "handbook.pdf"}},
+			lang: LangJapanese,
+			want: "資料「handbook.pdf」で読んだ",
+		},
+		{
+			name: "unknown source",
+			mem:  Memory[int]{},
+			lang: LangEnglish,
+			want: "noted",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SearchResult[int]{Memory: tt.mem, SearchedAt: searched}.Citation(tt.lang)
+			if got != tt.want {
+				t.Errorf("Citation() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLTM_SearchCarriesProvenance(t *testing.T) {
+	store := &mockStore{}
+	ltm := NewLTM[int](store, nil, DefaultLTMConfig())
+	ctx := context.Background()
+	prov := Provenance{Source: SourceUserMessage, MessageID: "msg-42", Turn: 7, ConversationID: "c1", Extractor: "fact-extractor-v2"}
+	if err := ltm.Save(ctx, &Memory[int]{ID: 1, Content: "x", Embedding: []float64{1, 0}, Provenance: prov}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	results, err := ltm.Search(ctx, SearchQuery{QueryEmbedding: []float64{1, 0}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].Memory.Provenance != prov {
+		t.Errorf("provenance not carried through: %+v", results)
+	}
+}
+
+func TestLTM_CitationUsesClock(t *testing.T) {
+	store := &mockStore{}
+	cfg := DefaultLTMConfig()
+	cfg.Clock = func() time.Time { return time.Date(2027, 1, 3, 0, 0, 0, 0, time.UTC) }
+	ltm := NewLTM[int](store, nil, cfg)
+	ctx := context.Background()
+	told := time.Date(2026, 12, 30, 0, 0, 0, 0, time.UTC)
+	mem := &Memory[int]{ID: 1, Embedding: []float64{1, 0}, Provenance: Provenance{Source: SourceUserMessage, SourceTime: told}}
+	if err := ltm.Save(ctx, mem); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	results, err := ltm.Search(ctx, SearchQuery{QueryEmbedding: []float64{1, 0}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	if got, want := results[0].Citation(LangEnglish), "you told me on 2026/12/30"; got != want {
+		t.Errorf("Citation() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,455 @@
+package memai
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ErrPIIDropped is returned when a memory contains PII whose policy is
+// RedactDrop. Nothing is stored.
+var ErrPIIDropped = errors.New("memai: memory contains PII that must not be stored")
+
+// PIIKind classifies personally identifiable information.
+type PIIKind string
+
+const (
+	PIIEmail      PIIKind = "email"
+	PIIPhone      PIIKind = "phone"       // Japanese and international phone numbers
+	PIIMyNumber   PIIKind = "my_number"   // Japanese Individual Number (マイナンバー)
+	PIIPostalCode PIIKind = "postal_code" // Japanese postal code (〒123-4567)
+	PIIAddress    PIIKind = "address"     // Japanese and English street addresses
+	PIICreditCard PIIKind = "credit_card"
+)
+
+// PIIMatch is one piece of PII found in a text. Start and End are byte
+// offsets.
+type PIIMatch struct {
+	Kind  PIIKind
+	Start int
+	End   int
+	Text  string
+}
+
+// PIIDetector finds one kind of PII. Implement this interface to add
+// detectors, e.g. for customer IDs.
+type PIIDetector interface {
+	DetectPII(text string) []PIIMatch
+}
+
+// RegexpDetector is a PIIDetector backed by a regular expression. Validate,
+// when set, rejects candidates (e.g. by checksum). With FoldWidth, Pattern
+// sees full-width digits and ＋－（） as ASCII, so `\d` and `\b` work on
+// ０９０-１２３４-５６７８; matches and Validate still get the original text.
+type RegexpDetector struct {
+	Kind      PIIKind
+	Pattern   *regexp.Regexp
+	Validate  func(match string) bool
+	FoldWidth bool
+}
+
+// DetectPII implements PIIDetector.
+func (d RegexpDetector) DetectPII(text string) []PIIMatch {
+	folded, offsets := text, []int(nil)
+	if d.FoldWidth {
+		folded, offsets = foldWidth(text)
+	}
+	var out []PIIMatch
+	for _, loc := range d.Pattern.FindAllStringIndex(folded, -1) {
+		if offsets != nil {
+			loc[0], loc[1] = offsets[loc[0]], offsets[loc[1]]
+		}
+		s := text[loc[0]:loc[1]]
+		if d.Validate != nil && !d.Validate(s) {
+			continue
+		}
+		out = append(out, PIIMatch{Kind: d.Kind, Start: loc[0], End: loc[1], Text: s})
+	}
+	return out
+}
+
+// foldWidth replaces full-width digits and ＋－（） in s with ASCII. offsets
+// maps each byte offset of the result, and its end, to the offset in s, or
+// is nil if nothing was folded.
+func foldWidth(s string) (string, []int) {
+	if !strings.ContainsFunc(s, isFoldable) {
+		return s, nil
+	}
+	var b strings.Builder
+	offsets := make([]int, 0, len(s)+1)
+	for i, r := range s {
+		if isFoldable(r) {
+			r -= 0xFEE0
+		}
+		for range utf8.RuneLen(r) {
+			offsets = append(offsets, i)
+		}
+		b.WriteRune(r)
+	}
+	return b.String(), append(offsets, len(s))
+}
+
+func isFoldable(r rune) bool {
+	return r >= '０' && r <= '９' || r == '＋' || r == '－' || r == '（' || r == '）'
+}
+
+// The numeric patterns consume the whole digit run so that the validators,
+// which check the digit count, never accept a prefix of a longer number.
+var (
+	emailPattern      = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`)
+	phonePattern      = regexp.MustCompile(`\+\d{1,3}[ \-]?(?:\(0\))?\d{1,4}(?:[ \-]?\d{2,4}){2,3}\b|\b0\d{1,4}-\d{1,4}-\d{3,4}\b|\b0\d{9,10}\b|\(0\d{1,4}\)\s?\d{1,4}-\d{3,4}\b`)
+	myNumberPattern   = regexp.MustCompile(`\b\d{4}[ \-]?\d{4}[ \-]?\d{4}(?:[ \-]?\d)*\b`)
+	postalCodePattern = regexp.MustCompile(`〒\s?\d{3}-?\d{4}\b|\b\d{3}-\d{4}\b`)
+	addressPatternJA  = regexp.MustCompile(`(?:東京都|北海道|大阪府|京都府|\p{Han}{2,3}県)\p{Han}{1,6}[市区町村郡](?:[\p{Han}\p{Katakana}ー0-9０-９\-－−]|丁目|番地)*`)
+	addressPatternEN  = regexp.MustCompile(`\b\d{1,5}(?: [A-Z][a-z]+)+ (?:Street|St|Avenue|Ave|Road|Rd|Boulevard|Blvd|Lane|Ln|Drive|Dr|Court|Ct|Way)\b\.?`)
+	cardPattern       = regexp.MustCompile(`\b\d(?:[ \-]?\d){12,}\b`)
+)
+
+// DefaultPIIDetectors returns the built-in detectors: email addresses,
+// Japanese and international phone numbers, My Number (check digit
+// verified), Japanese postal codes, Japanese and English street addresses,
+// and credit card numbers (Luhn verified). Numbers may be written with
+// full-width digits.
+func DefaultPIIDetectors() []PIIDetector {
+	return []PIIDetector{
+		RegexpDetector{Kind: PIIEmail, Pattern: emailPattern},
+		RegexpDetector{Kind: PIICreditCard, Pattern: cardPattern, Validate: validLuhn, FoldWidth: true},
+		RegexpDetector{Kind: PIIMyNumber, Pattern: myNumberPattern, Validate: validMyNumber, FoldWidth: true},
+		RegexpDetector{Kind: PIIPhone, Pattern: phonePattern, FoldWidth: true},
+		RegexpDetector{Kind: PIIPostalCode, Pattern: postalCodePattern, FoldWidth: true},
+		RegexpDetector{Kind: PIIAddress, Pattern: addressPatternJA},
+		RegexpDetector{Kind: PIIAddress, Pattern: addressPatternEN},
+	}
+}
+
+// RedactionPolicy selects what replaces detected PII.
+type RedactionPolicy string
+
+const (
+	// RedactMask replaces PII with its kind: "[EMAIL]".
+	RedactMask RedactionPolicy = "mask"
+	// RedactHash replaces PII with a keyed hash, so equal values still
+	// match: "[EMAIL:3f9a0c2b7d1e4a56]".
+	RedactHash RedactionPolicy = "hash"
+	// RedactTokenize stores the value in a TokenVault and replaces it with
+	// the token: "[EMAIL#tok_…]". Redactor.Reveal restores it.
+	RedactTokenize RedactionPolicy = "tokenize"
+	// RedactDrop refuses to store the memory (ErrPIIDropped).
+	RedactDrop RedactionPolicy = "drop"
+)
+
+// TokenVault keeps the original values behind RedactTokenize tokens. It
+// should live in a store with tighter access control than the memories.
+type TokenVault interface {
+	// Tokenize returns the token for value, creating one if needed.
+	Tokenize(ctx context.Context, kind PIIKind, value string) (string, error)
+	// Detokenize returns the value of token; ok is false for unknown tokens.
+	Detokenize(ctx context.Context, token string) (value string, ok bool, err error)
+}
+
+// MemoryTokenVault is an in-memory TokenVault. The same value always gets
+// the same token. It is safe for concurrent use.
+type MemoryTokenVault struct {
+	mu     sync.Mutex
+	tokens map[string]string // kind + value -> token
+	values map[string]string // token -> value
+}
+
+// NewMemoryTokenVault returns an empty in-memory vault.
+func NewMemoryTokenVault() *MemoryTokenVault {
+	return &MemoryTokenVault{tokens: make(map[string]string), values: make(map[string]string)}
+}
+
+// Tokenize implements TokenVault.
+func (v *MemoryTokenVault) Tokenize(_ context.Context, kind PIIKind, value string) (string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	key := string(kind) + "\x00" + value
+	if tok, ok := v.tokens[key]; ok {
+		return tok, nil
+	}
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	tok := "tok_" + hex.EncodeToString(b)
+	v.tokens[key] = tok
+	v.values[tok] = value
+	return tok, nil
+}
+
+// Detokenize implements TokenVault.
+func (v *MemoryTokenVault) Detokenize(_ context.Context, token string) (string, bool, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	value, ok := v.values[token]
+	return value, ok, nil
+}
+
+// RedactorConfig configures a Redactor.
+type RedactorConfig struct {
+	Detectors []PIIDetector               // PII detectors (default: DefaultPIIDetectors())
+	Policy    RedactionPolicy             // Policy for kinds not in Policies (default: RedactMask)
+	Policies  map[PIIKind]RedactionPolicy // Per-kind policy overrides
+	HashKey   []byte                      // HMAC key for RedactHash; required when it is used
+	Vault     TokenVault                  // Token store for RedactTokenize; required when it is used
+}
+
+// Redactor removes PII from text according to per-kind policies.
+type Redactor struct {
+	config RedactorConfig
+}
+
+// NewRedactor validates cfg and returns a Redactor. Hashing without a key
+// is rejected: phone numbers and My Numbers are few enough to brute-force an
+// unkeyed hash.
+func NewRedactor(cfg RedactorConfig) (*Redactor, error) {
+	if cfg.Detectors == nil {
+		cfg.Detectors = DefaultPIIDetectors()
+	}
+	if cfg.Policy == "" {
+		cfg.Policy = RedactMask
+	}
+	policies := []RedactionPolicy{cfg.Policy}
+	for _, p := range cfg.Policies {
+		policies = append(policies, p)
+	}
+	for _, p := range policies {
+		switch p {
+		case RedactMask, RedactDrop:
+		case RedactHash:
+			if len(cfg.HashKey) == 0 {
+				return nil, errors.New("redactor: RedactHash requires HashKey")
+			}
+		case RedactTokenize:
+			if cfg.Vault == nil {
+				return nil, errors.New("redactor: RedactTokenize requires Vault")
+			}
+		default:
+			return nil, fmt.Errorf("redactor: unknown policy %q", p)
+		}
+	}
+	return &Redactor{config: cfg}, nil
+}
+
+// Detect returns the PII found in text, ordered by position. Where matches
+// overlap, the longest wins.
+func (r *Redactor) Detect(text string) []PIIMatch {
+	var all []PIIMatch
+	for _, d := range r.config.Detectors {
+		all = append(all, d.DetectPII(text)...)
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].Start != all[j].Start {
+			return all[i].Start < all[j].Start
+		}
+		return all[i].End > all[j].End
+	})
+	var out []PIIMatch
+	for _, m := range all {
+		if n := len(out); n > 0 && m.Start < out[n-1].End {
+			if m.End-m.Start <= out[n-1].End-out[n-1].Start {
+				continue
+			}
+			out = out[:n-1]
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// Redact replaces the PII in text according to the configured policies. It
+// returns ErrPIIDropped if any match has the RedactDrop policy.
+func (r *Redactor) Redact(ctx context.Context, text string) (string, error) {
+	return r.redact(ctx, text, false)
+}
+
+// redact implements Redact. With query set, RedactDrop masks instead of
+// failing, since a search query is not stored.
+func (r *Redactor) redact(ctx context.Context, text string, query bool) (string, error) {
+	matches := r.Detect(text)
+	if len(matches) == 0 {
+		return text, nil
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		policy := r.policy(m.Kind)
+		if policy == RedactDrop {
+			if !query {
+				return "", fmt.Errorf("%w (%s)", ErrPIIDropped, m.Kind)
+			}
+			policy = RedactMask
+		}
+		label := strings.ToUpper(string(m.Kind))
+		b.WriteString(text[last:m.Start])
+		switch policy {
+		case RedactMask:
+			b.WriteString("[" + label + "]")
+		case RedactHash:
+			mac := hmac.New(sha256.New, r.config.HashKey)
+			mac.Write([]byte(m.Kind))
+			mac.Write([]byte{0})
+			mac.Write([]byte(m.Text))
+			b.WriteString("[" + label + ":" + hex.EncodeToString(mac.Sum(nil))[:16] + "]")
+		case RedactTokenize:
+			tok, err := r.config.Vault.Tokenize(ctx, m.Kind, m.Text)
+			if err != nil {
+				return "", fmt.Errorf("token vault error: %w", err)
+			}
+			b.WriteString("[" + label + "#" + tok + "]")
+		}
+		last = m.End
+	}
+	b.WriteString(text[last:])
+	return b.String(), nil
+}
+
+var tokenRefPattern = regexp.MustCompile(`\[[A-Z_]+#([A-Za-z0-9_\-]+)\]`)
+
+// Reveal restores tokenized PII in text from the vault. Unknown tokens are
+// left as they are.
+func (r *Redactor) Reveal(ctx context.Context, text string) (string, error) {
+	if r.config.Vault == nil {
+		return text, nil
+	}
+	var firstErr error
+	out := tokenRefPattern.ReplaceAllStringFunc(text, func(ref string) string {
+		tok := tokenRefPattern.FindStringSubmatch(ref)[1]
+		value, ok, err := r.config.Vault.Detokenize(ctx, tok)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if !ok {
+			return ref
+		}
+		return value
+	})
+	if firstErr != nil {
+		return "", fmt.Errorf("token vault error: %w", firstErr)
+	}
+	return out, nil
+}
+
+func (r *Redactor) policy(kind PIIKind) RedactionPolicy {
+	if p, ok := r.config.Policies[kind]; ok {
+		return p
+	}
+	return r.config.Policy
+}
+
+// redactMemory redacts the content and entities of mem in place.
+func redactMemory[ID comparable](ctx context.Context, r *Redactor, mem *Memory[ID]) error {
+	content, err := r.Redact(ctx, mem.Content)
+	if err != nil {
+		return err
+	}
+	var entities []string
+	for _, e := range mem.Entities {
+		e, err := r.Redact(ctx, e)
+		if err != nil {
+			return err
+		}
+		entities = append(entities, e)
+	}
+	mem.Content, mem.Entities = content, entities
+	return nil
+}
+
+// RedactingStore is a MemoryStore decorator that redacts PII from memories
+// before they reach the wrapped store. Embeddings computed before SaveMemory
+// were made from the raw text; set LTMConfig.Redactor as well to keep PII
+// away from the embedding provider.
+type RedactingStore[ID comparable] struct {
+	storeDecorator[ID]
+	redactor *Redactor
+}
+
+// NewRedactingStore wraps store so that every saved memory is redacted by r.
+// Optional capabilities of store are passed through.
+func NewRedactingStore[ID comparable](store MemoryStore[ID], r *Redactor) *RedactingStore[ID] {
+	return &RedactingStore[ID]{storeDecorator: storeDecorator[ID]{inner: store}, redactor: r}
+}
+
+// SaveMemory redacts mem in place and saves it.
+func (s *RedactingStore[ID]) SaveMemory(ctx context.Context, mem *Memory[ID]) error {
+	if err := redactMemory(ctx, s.redactor, mem); err != nil {
+		return err
+	}
+	return s.inner.SaveMemory(ctx, mem)
+}
+
+// UpdateContent redacts content and forwards it to the wrapped store.
+func (s *RedactingStore[ID]) UpdateContent(ctx context.Context, id ID, content string) error {
+	content, err := s.redactor.Redact(ctx, content)
+	if err != nil {
+		return err
+	}
+	return s.storeDecorator.UpdateContent(ctx, id, content)
+}
+
+// validLuhn reports whether the digits of s (13 to 19) pass the Luhn check.
+func validLuhn(s string) bool {
+	digits := onlyDigits(s)
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+	sum := 0
+	for i := range digits {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// validMyNumber reports whether s is 12 digits with a valid My Number check
+// digit.
+func validMyNumber(s string) bool {
+	digits := onlyDigits(s)
+	if len(digits) != 12 {
+		return false
+	}
+	sum := 0
+	for n := 1; n <= 11; n++ {
+		p := int(digits[11-n] - '0')
+		q := n + 1
+		if n > 6 {
+			q = n - 5
+		}
+		sum += p * q
+	}
+	check := 11 - sum%11
+	if sum%11 <= 1 {
+		check = 0
+	}
+	return int(digits[11]-'0') == check
+}
+
+// onlyDigits returns the digits of s as ASCII, folding full-width digits.
+func onlyDigits(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r >= '０' && r <= '９':
+			b.WriteRune(r - 0xFEE0)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,152 @@
+package memai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRedactor_DetectsBuiltInKinds(t *testing.T) {
+	r, err := NewRedactor(RedactorConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		text string
+		kind PIIKind
+		want string
+	}{
+		{"連絡先は taro.yamada@example.co.jp です", PIIEmail, "taro.yamada@example.co.jp"},
+		{"携帯は090-1234-5678です", PIIPhone, "090-1234-5678"},
+		{"call me at +1 415 555 0132", PIIPhone, "+1 415 555 0132"},
+		{"call +81-3-1234-5678 tomorrow", PIIPhone, "+81-3-1234-5678"},
+		{"マイナンバーは5123 4567 8906", PIIMyNumber, "5123 4567 8906"},
+		{"〒100-0001 に送って", PIIPostalCode, "〒100-0001"},
+		{"東京都港区芝公園4-2-8に住んでいる", PIIAddress, "東京都港区芝公園4-2-8"},
+		{"I live at 221 Baker Street now", PIIAddress, "221 Baker Street"},
+		{"card 4111 1111 1111 1111 expires soon", PIICreditCard, "4111 1111 1111 1111"},
+		{"電話は０９０-１２３４-５６７８です", PIIPhone, "０９０-１２３４-５６７８"},
+		{"〒１００-０００１", PIIPostalCode, "〒１００-０００１"},
+		{"マイナンバーは５１２３ ４５６７ ８９０６", PIIMyNumber, "５１２３ ４５６７ ８９０６"},
+		{"カード４１１１－１１１１－１１１１－１１１１で払う", PIICreditCard, "４１１１－１１１１－１１１１－１１１１"},
+	}
+	for _, tt := range tests {
+		matches := r.Detect(tt.text)
+		if len(matches) != 1 || matches[0].Kind != tt.kind || matches[0].Text != tt.want {
+			t.Errorf("Detect(%q) = %+v, want one %s %q", tt.text, matches, tt.kind, tt.want)
+		}
+	}
+}
+
+func TestRedactor_ChecksumsRejectLookalikes(t *testing.T) {
+	r, _ := NewRedactor(RedactorConfig{})
+	for _, text := range []string{"注文番号 5123 4567 8907", "ref 4111 1111 1111 1112", "注文番号５１２３４５６７８９０７"} {
+		if m := r.Detect(text); len(m) != 0 {
+			t.Errorf("Detect(%q) = %+v, want no match", text, m)
+		}
+	}
+}
+
+func TestRedactor_Policies(t *testing.T) {
+	ctx := context.Background()
+	vault := NewMemoryTokenVault()
+	r, err := NewRedactor(RedactorConfig{
+		Policy: RedactMask,
+		Policies: map[PIIKind]RedactionPolicy{
+			PIIEmail: RedactTokenize,
+			PIIPhone: RedactHash,
+		},
+		HashKey: []byte("secret"),
+		Vault:   vault,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := "mail a@example.com or 090-1234-5678, 〒100-0001"
+	out, err := r.Redact(ctx, text)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(out, "a@example.com") || strings.Contains(out, "5678") || strings.Contains(out, "100-0001") {
+		t.Fatalf("PII left in output: %q", out)
+	}
+	if !strings.Contains(out, "[EMAIL#tok_") || !strings.Contains(out, "[PHONE:") || !strings.Contains(out, "[POSTAL_CODE]") {
+		t.Errorf("unexpected output: %q", out)
+	}
+	again, _ := r.Redact(ctx, text)
+	if again != out {
+		t.Errorf("redaction should be deterministic: %q vs %q", again, out)
+	}
+	revealed, err := r.Reveal(ctx, out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(revealed, "a@example.com") {
+		t.Errorf("Reveal should restore tokenized values: %q", revealed)
+	}
+}
+
+func TestRedactor_Drop(t *testing.T) {
+	r, _ := NewRedactor(RedactorConfig{Policies: map[PIIKind]RedactionPolicy{PIIMyNumber: RedactDrop}})
+	_, err := r.Redact(context.Background(), "番号は512345678906")
+	if !errors.Is(err, ErrPIIDropped) {
+		t.Errorf("expected ErrPIIDropped, got %v", err)
+	}
+}
+
+func TestNewRedactor_RequiresKeyAndVault(t *testing.T) {
+	if _, err := NewRedactor(RedactorConfig{Policy: RedactHash}); err == nil {
+		t.Error("expected error for RedactHash without HashKey")
+	}
+	if _, err := NewRedactor(RedactorConfig{Policy: RedactTokenize}); err == nil {
+		t.Error("expected error for RedactTokenize without Vault")
+	}
+}
+
+func TestLTM_SaveRedactsBeforeEmbedding(t *testing.T) {
+	r, _ := NewRedactor(RedactorConfig{})
+	var embedded []string
+	embFn := func(_ context.Context, text string) ([]float64, error) {
+		embedded = append(embedded, text)
+		return []float64{1, 0, 0}, nil
+	}
+	store := &mockStore{}
+	cfg := DefaultLTMConfig()
+	cfg.Redactor = r
+	ltm := NewLTM[int](store, embFn, cfg)
+
+	if err := ltm.Save(context.Background(), &Memory[int]{ID: 1, Content: "電話は090-1234-5678"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := ltm.Search(context.Background(), SearchQuery{Query: "090-1234-5678に電話"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if store.memories[0].Content != "電話は[PHONE]" {
+		t.Errorf("stored content = %q", store.memories[0].Content)
+	}
+	for _, text := range embedded {
+		if strings.Contains(text, "5678") {
+			t.Errorf("PII reached the embedding function: %q", text)
+		}
+	}
+}
+
+func TestRedactingStore(t *testing.T) {
+	r, _ := NewRedactor(RedactorConfig{})
+	inner := &graphStore{}
+	store := NewRedactingStore[int](inner, r)
+	mem := &Memory[int]{ID: 1, Content: "a@example.com に送る", Entities: []string{"a@example.com"}}
+	if err := store.SaveMemory(context.Background(), mem); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := inner.memories[0]; got.Content != "[EMAIL] に送る" || got.Entities[0] != "[EMAIL]" {
+		t.Errorf("unexpected stored memory: %+v", got)
+	}
+	if _, ok := capability[GraphStore[int]](MemoryStore[int](store)); !ok {
+		t.Error("GraphStore capability should pass through the decorator")
+	}
+	if _, ok := capability[GraphStore[int]](MemoryStore[int](NewRedactingStore[int](&mockStore{}, r))); ok {
+		t.Error("decorator should not report a capability its store lacks")
+	}
+}
@@ -0,0 +1,96 @@
+package memai
+
+import (
+	"context"
+	"fmt"
+)
+
+// ReembedOptions configures an embedding migration run by LTM.Reembed.
+type ReembedOptions struct {
+	Model     string                // Model ID of the new embedding function (required)
+	BatchSize int                   // Memories processed between progress reports (default: 100)
+	Progress  func(ReembedProgress) // Called after every batch; may be nil
+}
+
+// ReembedProgress reports how far an embedding migration has got.
+type ReembedProgress struct {
+	Total   int // Memories in the store
+	Skipped int // Memories already embedded with the target model
+	Done    int // Memories re-embedded by this run
+}
+
+// Remaining returns the number of memories still to be migrated.
+func (p ReembedProgress) Remaining() int {
+	return p.Total - p.Skipped - p.Done
+}
+
+// Reembed rewrites every stored embedding with newFn, tagging each memory
+// with opts.Model. The store must implement EmbeddingUpdater.
+//
+// The migration is resumable: memories already tagged with opts.Model are
+// skipped, so after a failure or cancellation Reembed can simply be called
+// again. Context cancellation is checked between batches and the progress
+// made so far is returned alongside the error.
+//
+// A namespaced LTM only migrates its own namespace.
+//
+// Reembed does not change the LTM's own embedding function. Once it
+// completes, construct a new LTM with newFn and LTMConfig.EmbeddingModel set
+// to opts.Model.
+func (l *LTM[ID]) Reembed(ctx context.Context, newFn EmbeddingFunc, opts ReembedOptions) (ReembedProgress, error) {
+	var progress ReembedProgress
+	if newFn == nil {
+		return progress, fmt.Errorf("reembed: nil embedding function")
+	}
+	if opts.Model == "" {
+		return progress, fmt.Errorf("reembed: target model is required")
+	}
+	updater, ok := capability[EmbeddingUpdater[ID]](l.store)
+	if !ok {
+		return progress, fmt.Errorf("reembed: %w (EmbeddingUpdater)", ErrUnsupported)
+	}
+	batch := opts.BatchSize
+	if batch <= 0 {
+		batch = 100
+	}
+
+	ctx, ns, err := l.resolveNamespace(ctx, Namespace{})
+	if err != nil {
+		return progress, err
+	}
+	memories, err := l.memories(ctx, ns)
+	if err != nil {
+		return progress, fmt.Errorf("memory store error: %w", err)
+	}
+	progress.Total = len(memories)
+
+	var pending []Memory[ID]
+	for _, mem := range memories {
+		if mem.EmbeddingModel == opts.Model && len(mem.Embedding) > 0 {
+			progress.Skipped++
+			continue
+		}
+		pending = append(pending, mem)
+	}
+
+	for start := 0; start < len(pending); start += batch {
+		if err := ctx.Err(); err != nil {
+			return progress, err
+		}
+		end := min(start+batch, len(pending))
+		for _, mem := range pending[start:end] {
+			emb, err := newFn(ctx, mem.Content)
+			if err != nil {
+				return progress, fmt.Errorf("embedding generation failed: %w", err)
+			}
+			if err := updater.UpdateEmbedding(ctx, mem.ID, emb, opts.Model); err != nil {
+				return progress, fmt.Errorf("memory store error: %w", err)
+			}
+			progress.Done++
+		}
+		if opts.Progress != nil {
+			opts.Progress(progress)
+		}
+	}
+	return progress, nil
+}
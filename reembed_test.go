@@ -0,0 +1,71 @@
+package memai
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestLTM_Reembed(t *testing.T) {
+	store := &mockStore{
+		memories: []Memory[int]{
+			{ID: 1, Content: "a", Embedding: []float64{1, 0}, EmbeddingModel: "old"},
+			{ID: 2, Content: "b", Embedding: []float64{0, 1}, EmbeddingModel: "old"},
+			{ID: 3, Content: "c", Embedding: []float64{1, 0, 0}, EmbeddingModel: "new", EmbeddingDim: 3},
+		},
+	}
+	ltm := NewLTM(store, nil, DefaultLTMConfig())
+	newFn := func(_ context.Context, _ string) ([]float64, error) { return []float64{0, 0, 1}, nil }
+
+	var reports []ReembedProgress
+	p, err := ltm.Reembed(context.Background(), newFn, ReembedOptions{
+		Model:     "new",
+		BatchSize: 1,
+		Progress:  func(p ReembedProgress) { reports = append(reports, p) },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Done != 2 || p.Skipped != 1 || p.Remaining() != 0 {
+		t.Errorf("unexpected progress: %+v", p)
+	}
+	if len(reports) != 2 {
+		t.Errorf("expected a progress report per batch, got %d", len(reports))
+	}
+	for _, m := range store.memories {
+		if m.EmbeddingModel != "new" || m.EmbeddingDim != 3 {
+			t.Errorf("memory %d not migrated: %q / %d", m.ID, m.EmbeddingModel, m.EmbeddingDim)
+		}
+	}
+}
+
+// A failed run must be resumable: memories migrated before the failure are
+// skipped on the next call.
+func TestLTM_ReembedResume(t *testing.T) {
+	store := &mockStore{
+		memories: []Memory[int]{
+			{ID: 1, Content: "a", Embedding: []float64{1, 0}},
+			{ID: 2, Content: "fail", Embedding: []float64{0, 1}},
+		},
+	}
+	ltm := NewLTM(store, nil, DefaultLTMConfig())
+	failing := func(_ context.Context, text string) ([]float64, error) {
+		if text == "fail" {
+			return nil, errors.New("provider down")
+		}
+		return []float64{1, 1, 1}, nil
+	}
+	p, err := ltm.Reembed(context.Background(), failing, ReembedOptions{Model: "new"})
+	if err == nil || p.Done != 1 {
+		t.Fatalf("expected failure after 1 memory, got %+v, %v", p, err)
+	}
+
+	ok := func(_ context.Context, _ string) ([]float64, error) { return []float64{1, 1, 1}, nil }
+	p, err = ltm.Reembed(context.Background(), ok, ReembedOptions{Model: "new"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Skipped != 1 || p.Done != 1 {
+		t.Errorf("resume should skip migrated memory: %+v", p)
+	}
+}
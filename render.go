@@ -0,0 +1,217 @@
+package memai
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// RenderFormat selects the shape of rendered memory context.
+type RenderFormat string
+
+const (
+	FormatMarkdown RenderFormat = "markdown"
+	FormatXML      RenderFormat = "xml"
+	FormatJSON     RenderFormat = "json"
+)
+
+// RenderData is the input of a Renderer template. Build it from a packed
+// context with NewRenderData.
+type RenderData struct {
+	Emotion  *EmotionalState `json:"emotion,omitempty"`
+	Topics   []RenderTopic   `json:"topics"`
+	Memories []RenderMemory  `json:"memories"`
+}
+
+// RenderTopic is a working-memory item as seen by templates.
+type RenderTopic struct {
+	Topic      string  `json:"topic"`
+	Text       string  `json:"text"`
+	Activation float64 `json:"activation"`
+	Emotional  bool    `json:"emotional,omitempty"`
+	Truncated  bool    `json:"truncated,omitempty"`
+}
+
+// RenderMemory is a recalled long-term memory as seen by templates.
+type RenderMemory struct {
+	Text      string     `json:"text"`
+	Date      string     `json:"date,omitempty"`   // EventDate, or the creation date
+	Thread    string     `json:"thread,omitempty"` // ThreadKey
+	Kind      MemoryKind `json:"kind,omitempty"`
+	Score     float64    `json:"score"`
+	Truncated bool       `json:"truncated,omitempty"`
+}
+
+// NewRenderData converts the emotional state and a packed context into
+// template input. emotion may be nil.
+func NewRenderData[ID comparable](emotion *EmotionalState, packed PackedContext[ID]) RenderData {
+	data := RenderData{Emotion: emotion, Topics: []RenderTopic{}, Memories: []RenderMemory{}}
+	for _, it := range packed.Items {
+		switch {
+		case it.WorkingMemory != nil:
+			data.Topics = append(data.Topics, RenderTopic{
+				Topic:      it.WorkingMemory.Topic,
+				Text:       it.Text,
+				Activation: it.WorkingMemory.Activation,
+				Emotional:  it.WorkingMemory.Emotional,
+				Truncated:  it.Truncated,
+			})
+		case it.Memory != nil:
+			mem := it.Memory.Memory
+			date := mem.EventDate
+			if date == "" && !mem.CreatedAt.IsZero() {
+				date = mem.CreatedAt.Format("2006-01-02")
+			}
+			data.Memories = append(data.Memories, RenderMemory{
+				Text:      it.Text,
+				Date:      date,
+				Thread:    mem.ThreadKey,
+				Kind:      mem.Kind,
+				Score:     it.Memory.Score,
+				Truncated: it.Truncated,
+			})
+		}
+	}
+	return data
+}
+
+// renderLabels holds the headings used by the default templates.
+var renderLabels = map[Language]map[string]string{
+	LangEnglish: {
+		"emotion":   "Current emotional state",
+		"intensity": "intensity",
+		"topics":    "Current topics",
+		"memories":  "Recalled memories",
+		"thread":    "thread",
+		"emotional": "emotional",
+	},
+	LangJapanese: {
+		"emotion":   "現在の感情",
+		"intensity": "強度",
+		"topics":    "現在の話題",
+		"memories":  "思い出した記憶",
+		"thread":    "スレッド",
+		"emotional": "感情的",
+	},
+}
+
+// emotionNames are the display names of emotions in each language.
+var emotionNames = map[Language]map[EmotionType]string{
+	LangEnglish: {
+		EmotionJoy: "joy", EmotionSadness: "sadness", EmotionAnger: "anger",
+		EmotionFear: "fear", EmotionSurprise: "surprise", EmotionNeutral: "neutral",
+	},
+	LangJapanese: {
+		EmotionJoy: "喜び", EmotionSadness: "悲しみ", EmotionAnger: "怒り",
+		EmotionFear: "不安", EmotionSurprise: "驚き", EmotionNeutral: "中立",
+	},
+}
+
+// defaultTemplates are the built-in templates. Each defines "context" (the
+// whole output) from the overridable blocks "emotion", "topics" and
+// "memories".
+var defaultTemplates = map[RenderFormat]string{
+	FormatMarkdown: `
+{{- define "emotion"}}{{with .Emotion}}## {{label "emotion"}}
+- {{emotionName .Primary}} ({{label "intensity"}}: {{printf "%.1f" .Intensity}})
+
+{{end}}{{end}}
+{{- define "topics"}}{{if .Topics}}## {{label "topics"}}
+{{range .Topics}}- {{.Text}}{{if .Emotional}} ({{label "emotional"}}){{end}}
+{{end}}
+{{end}}{{end}}
+{{- define "memories"}}{{if .Memories}}## {{label "memories"}}
+{{range .Memories}}- {{if .Date}}[{{.Date}}] {{end}}{{.Text}}{{if .Thread}} ({{label "thread"}}: {{.Thread}}){{end}}
+{{end}}
+{{end}}{{end}}
+{{- define "context"}}{{template "emotion" .}}{{template "topics" .}}{{template "memories" .}}{{end}}`,
+
+	FormatXML: `
+{{- define "emotion"}}{{with .Emotion}}  <emotional_state primary="{{.Primary}}" intensity="{{printf "%.2f" .Intensity}}" valence="{{printf "%.2f" .Valence}}">{{xml (emotionName .Primary)}}</emotional_state>
+{{end}}{{end}}
+{{- define "topics"}}{{if .Topics}}  <working_memory>
+{{range .Topics}}    <topic{{if .Emotional}} emotional="true"{{end}}>{{xml .Text}}</topic>
+{{end}}  </working_memory>
+{{end}}{{end}}
+{{- define "memories"}}{{if .Memories}}  <long_term_memory>
+{{range .Memories}}    <memory{{if .Date}} date="{{xml .Date}}"{{end}}{{if .Thread}} thread="{{xml .Thread}}"{{end}}{{if .Kind}} kind="{{.Kind}}"{{end}}>{{xml .Text}}</memory>
+{{end}}  </long_term_memory>
+{{end}}{{end}}
+{{- define "context"}}<memory_context>
+{{template "emotion" .}}{{template "topics" .}}{{template "memories" .}}</memory_context>
+{{end}}`,
+
+	FormatJSON: `{{define "context"}}{{json .}}
+{{end}}`,
+}
+
+// Renderer formats memory context for a system prompt using text/template.
+// The default templates can be replaced block by block with Override.
+type Renderer struct {
+	tmpl *template.Template
+}
+
+// NewRenderer returns a Renderer for format with headings and emotion names
+// in lang (LangJapanese or LangEnglish).
+func NewRenderer(format RenderFormat, lang Language) (*Renderer, error) {
+	text, ok := defaultTemplates[format]
+	if !ok {
+		return nil, fmt.Errorf("render: unknown format %q", format)
+	}
+	labels, ok := renderLabels[lang]
+	if !ok {
+		return nil, fmt.Errorf("render: unsupported language %q", lang)
+	}
+	funcs := template.FuncMap{
+		"label":       func(key string) string { return labels[key] },
+		"emotionName": func(e EmotionType) string { return emotionNames[lang][e] },
+		"xml":         xmlEscape,
+		"json":        jsonIndent,
+	}
+	tmpl, err := template.New("context").Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("render: %w", err)
+	}
+	return &Renderer{tmpl: tmpl}, nil
+}
+
+// Override parses text into the renderer, replacing any block it defines.
+// Define "emotion", "topics" or "memories" to change one section, or
+// "context" to replace the whole layout. The functions label, emotionName,
+// xml and json are available. As with text/template, a definition whose
+// body is only whitespace does not replace an existing block.
+func (r *Renderer) Override(text string) error {
+	if _, err := r.tmpl.Parse(text); err != nil {
+		return fmt.Errorf("render: %w", err)
+	}
+	return nil
+}
+
+// Render writes data to w.
+func (r *Renderer) Render(w io.Writer, data RenderData) error {
+	return r.tmpl.ExecuteTemplate(w, "context", data)
+}
+
+// RenderString renders data to a string.
+func (r *Renderer) RenderString(data RenderData) (string, error) {
+	var buf bytes.Buffer
+	if err := r.Render(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func jsonIndent(v any) (string, error) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	return string(b), err
+}
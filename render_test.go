@@ -0,0 +1,93 @@
+package memai
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func sampleRenderData() RenderData {
+	packed := BuildContext([]*WorkingMemoryItem{
+		{Topic: "旅行", Content: "京都旅行の計画", Activation: 0.9, Emotional: true},
+	}, []SearchResult[int]{
+		{Memory: Memory[int]{ID: 1, Content: "清水寺に行きたい <本当>", EventDate: "2026-06-17", ThreadKey: "trip"}, Score: 0.8},
+	}, ContextOptions{Budget: 100})
+	return NewRenderData(&EmotionalState{Primary: EmotionJoy, Intensity: 0.6, Valence: 0.48}, packed)
+}
+
+func TestRenderer_MarkdownJapanese(t *testing.T) {
+	r, err := NewRenderer(FormatMarkdown, LangJapanese)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out, err := r.RenderString(sampleRenderData())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `## 現在の感情
+- 喜び (強度: 0.6)
+
+## 現在の話題
+- 京都旅行の計画 (感情的)
+
+## 思い出した記憶
+- [2026-06-17] 清水寺に行きたい <本当> (スレッド: trip)
+
+`
+	if out != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestRenderer_XMLEscapes(t *testing.T) {
+	r, err := NewRenderer(FormatXML, LangEnglish)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out, err := r.RenderString(sampleRenderData())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, s := range []string{"<memory_context>", `primary="joy"`, `<memory date="2026-06-17" thread="trip">`, "&lt;本当&gt;"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output missing %q:\n%s", s, out)
+		}
+	}
+}
+
+func TestRenderer_JSON(t *testing.T) {
+	r, err := NewRenderer(FormatJSON, LangEnglish)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out, err := r.RenderString(sampleRenderData())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded RenderData
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(decoded.Topics) != 1 || len(decoded.Memories) != 1 || decoded.Memories[0].Thread != "trip" {
+		t.Errorf("unexpected decoded data: %+v", decoded)
+	}
+}
+
+func TestRenderer_Override(t *testing.T) {
+	r, err := NewRenderer(FormatMarkdown, LangEnglish)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.Override(`{{define "emotion"}}{{with .Emotion}}Mood: {{emotionName .Primary}}
+
+{{end}}{{end}}`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out, err := r.RenderString(sampleRenderData())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(out, "Mood: joy\n\n## Current topics") {
+		t.Errorf("emotion block should be overridden:\n%s", out)
+	}
+}
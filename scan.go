@@ -0,0 +1,117 @@
+package memai
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+)
+
+const (
+	// minMemoriesPerWorker keeps small stores on a single goroutine, where
+	// the cost of spawning workers would outweigh the similarity math.
+	minMemoriesPerWorker = 2048
+
+	// ctxCheckInterval is how many memories a worker scores between context
+	// checks, so cancellation is noticed mid-scan without a per-item cost.
+	ctxCheckInterval = 256
+)
+
+// scan scores memories against the query embedding, returning the memories
+// that pass the similarity threshold (in store order) and the number of
+// incompatible embeddings seen. Large sets are split across a worker pool
+// sized from LTMConfig.Workers. The scan stops with ctx.Err() as soon as the
+// context is cancelled or its deadline passes.
+func (l *LTM[ID]) scan(ctx context.Context, sc *scoring, memories []Memory[ID]) ([]SearchResult[ID], int, error) {
+	workers := l.workers(len(memories))
+	if workers == 1 {
+		return l.scanRange(ctx, sc, memories)
+	}
+
+	type part struct {
+		results      []SearchResult[ID]
+		incompatible int
+		err          error
+	}
+	parts := make([]part, workers)
+	size := (len(memories) + workers - 1) / workers
+
+	var wg sync.WaitGroup
+	for w := range parts {
+		lo := w * size
+		hi := min(lo+size, len(memories))
+		if lo >= hi {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p := &parts[w]
+			p.results, p.incompatible, p.err = l.scanRange(ctx, sc, memories[lo:hi])
+		}()
+	}
+	wg.Wait()
+
+	// Concatenate in chunk order so the outcome matches a sequential scan.
+	var results []SearchResult[ID]
+	incompatible := 0
+	for _, p := range parts {
+		if p.err != nil {
+			return nil, 0, p.err
+		}
+		results = append(results, p.results...)
+		incompatible += p.incompatible
+	}
+	return results, incompatible, nil
+}
+
+// scanRange scores a contiguous slice of memories on the calling goroutine.
+func (l *LTM[ID]) scanRange(ctx context.Context, sc *scoring, memories []Memory[ID]) ([]SearchResult[ID], int, error) {
+	var results []SearchResult[ID]
+	incompatible := 0
+	for i, mem := range memories {
+		if i%ctxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, 0, err
+			}
+		}
+		if len(mem.Embedding) == 0 || !sc.admits(mem.Entities) {
+			continue
+		}
+		// A vector from another model or of another size is not "dissimilar",
+		// it is simply not comparable.
+		if !l.compatible(mem, len(sc.embedding)) {
+			incompatible++
+			continue
+		}
+
+		// Inclusion is decided by cosine similarity alone (with emotional
+		// priming); score only affects ranking.
+		sim := cosineWithNorm(sc.embedding, mem.Embedding, sc.norm)
+		if sim < sc.threshold {
+			continue
+		}
+		results = append(results, SearchResult[ID]{Memory: mem, Score: l.score(sc, mem, sim), SearchedAt: sc.now})
+	}
+	return results, incompatible, nil
+}
+
+// scoring holds the per-search state shared by all scan workers. It is
+// read-only once the scan starts.
+type scoring struct {
+	query     SearchQuery
+	embedding []float64
+	norm      float64
+	threshold float64
+	entities  map[string]struct{} // entityKey of each query entity
+	now       time.Time
+}
+
+// workers returns the number of goroutines to score n memories with.
+func (l *LTM[ID]) workers(n int) int {
+	w := l.config.Workers
+	if w <= 0 {
+		w = runtime.GOMAXPROCS(0)
+	}
+	return max(1, min(w, n/minMemoriesPerWorker))
+}
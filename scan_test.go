@@ -0,0 +1,78 @@
+package memai
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+func largeStore(n int) *mockStore {
+	store := &mockStore{}
+	for i := 0; i < n; i++ {
+		x := float64(i%100) / 100
+		store.memories = append(store.memories, Memory[int]{
+			ID: i, Content: "m", Embedding: []float64{1, x, 1 - x},
+		})
+	}
+	return store
+}
+
+// The parallel scan must return exactly what a single-goroutine scan does.
+func TestLTM_ParallelMatchesSequential(t *testing.T) {
+	store := largeStore(3 * minMemoriesPerWorker)
+	q := SearchQuery{QueryEmbedding: []float64{1, 0.5, 0.5}}
+
+	seqCfg := DefaultLTMConfig()
+	seqCfg.TopK = 0
+	seqCfg.Workers = 1
+	parCfg := seqCfg
+	parCfg.Workers = 4
+
+	seq, err := NewLTM(store, nil, seqCfg).Search(context.Background(), q)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	par, err := NewLTM(store, nil, parCfg).Search(context.Background(), q)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(seq) != len(par) {
+		t.Fatalf("result count differs: %d vs %d", len(seq), len(par))
+	}
+	for i := range seq {
+		if seq[i].Memory.ID != par[i].Memory.ID || seq[i].Score != par[i].Score {
+			t.Fatalf("result %d differs: %+v vs %+v", i, seq[i], par[i])
+		}
+	}
+}
+
+func TestLTM_SearchHonoursCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cfg := DefaultLTMConfig()
+	cfg.Workers = 4
+	_, err := NewLTM(largeStore(3*minMemoriesPerWorker), nil, cfg).Search(ctx, SearchQuery{QueryEmbedding: []float64{1, 0, 0}})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestLTM_SearchSeesChangedEmbedding(t *testing.T) {
+	store := &mockStore{memories: []Memory[int]{{ID: 1, Embedding: []float64{0, 10}}}}
+	ltm := NewLTM[int](store, nil, DefaultLTMConfig())
+	ctx := context.Background()
+	q := SearchQuery{QueryEmbedding: []float64{1, 0}}
+	if results, err := ltm.Search(ctx, q); err != nil || len(results) != 0 {
+		t.Fatalf("expected no results, got %+v (%v)", results, err)
+	}
+	// Another writer changes the vector in place, same model and dimension.
+	store.memories[0].Embedding[0], store.memories[0].Embedding[1] = 3, 4
+	results, err := ltm.Search(ctx, q)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 || math.Abs(results[0].Score-0.6) > 1e-9 {
+		t.Errorf("expected similarity 0.6 for the new vector, got %+v", results)
+	}
+}
@@ -0,0 +1,93 @@
+package memai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// UpdateContext performs a full STM cycle like Update, adding semantic
+// refresh when STMConfig.EmbeddingFunc is set: items whose keywords do not
+// match the message are refreshed if their topic embedding is at least
+// SemanticThreshold similar to the message, by RefreshBoost scaled by the
+// similarity. "where should we eat tonight?" thus refreshes the topic
+// "dinner plans".
+//
+// Keyword matching stays the fast path: the message is only embedded when
+// some item does not match it by keyword, so an empty STM or a message
+// mentioning every item costs no embedding call. Topic embeddings are
+// computed once per item, when it first needs one, and cached in
+// TopicEmbedding. Embedding calls run without holding the STM's lock. If an
+// embedding call fails the error is returned and the STM is left unchanged;
+// call Update to fall back to keyword matching. Without an EmbeddingFunc,
+// UpdateContext is Update.
+func (s *STM) UpdateContext(ctx context.Context, turn int, message string, emotion *EmotionalState) error {
+	return s.UpdateContextAt(ctx, turn, s.now(), message, emotion)
+}
+
+// UpdateContextAt is UpdateContext at the given time (see UpdateAt).
+func (s *STM) UpdateContextAt(ctx context.Context, turn int, now time.Time, message string, emotion *EmotionalState) error {
+	s.mu.Lock()
+	embed := s.config.EmbeddingFunc
+	unmatched := false
+	var topics []string
+	if embed != nil {
+		for _, item := range s.items {
+			if s.itemMatchesMessage(item, message) {
+				continue
+			}
+			unmatched = true
+			if item.TopicEmbedding == nil {
+				topics = append(topics, topicText(item))
+			}
+		}
+	}
+	s.mu.Unlock()
+	if !unmatched {
+		s.UpdateAt(turn, now, message, emotion)
+		return nil
+	}
+
+	msgVec, err := embed(ctx, message)
+	if err != nil {
+		return fmt.Errorf("embedding generation failed: %w", err)
+	}
+	vecs := make(map[string][]float64, len(topics))
+	for _, topic := range topics {
+		if _, ok := vecs[topic]; ok {
+			continue
+		}
+		vec, err := embed(ctx, topic)
+		if err != nil {
+			return fmt.Errorf("embedding generation failed: %w", err)
+		}
+		vecs[topic] = vec
+	}
+
+	now = now.Round(0).UTC()
+	s.mu.Lock()
+	defer s.unlock()
+	// Items added while embedding was in progress have no vector yet and
+	// are matched by keywords only this turn.
+	for _, item := range s.items {
+		if vec, ok := vecs[topicText(item)]; ok && item.TopicEmbedding == nil {
+			item.TopicEmbedding = vec
+		}
+	}
+	s.turn = max(s.turn, turn)
+	s.decay(turn, now)
+	s.markEmotional(message, emotion)
+	s.refreshSemantic(message, msgVec)
+	s.evict()
+	return nil
+}
+
+// topicText is the text embedded for an item: its topic, or its keywords
+// if it has none.
+func topicText(item *WorkingMemoryItem) string {
+	if item.Topic != "" {
+		return item.Topic
+	}
+	return strings.Join(item.Keywords, " ")
+}
@@ -0,0 +1,143 @@
+package memai
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// conceptEmbedder maps texts to fixed vectors, so tests can decide which
+// texts are paraphrases.
+type conceptEmbedder struct {
+	vecs  map[string][]float64
+	calls map[string]int
+	err   error
+}
+
+func (e *conceptEmbedder) embed(_ context.Context, text string) ([]float64, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	e.calls[text]++
+	if v, ok := e.vecs[text]; ok {
+		return v, nil
+	}
+	return []float64{0, 0, 1}, nil
+}
+
+func newConceptEmbedder() *conceptEmbedder {
+	return &conceptEmbedder{
+		vecs: map[string][]float64{
+			"dinner plans":                 {1, 0, 0},
+			"where should we eat tonight?": {0.9, 0.1, 0},
+			"budget":                       {0, 1, 0},
+		},
+		calls: make(map[string]int),
+	}
+}
+
+func TestSTM_UpdateContextSemanticRefresh(t *testing.T) {
+	e := newConceptEmbedder()
+	config := DefaultSTMConfig()
+	config.EmbeddingFunc = e.embed
+	stm := NewSTM(config)
+	stm.SetItems([]*WorkingMemoryItem{
+		{Topic: "dinner plans", Keywords: []string{"dinner"}, Activation: 0.5},
+		{Topic: "budget", Keywords: []string{"budget"}, Activation: 0.5},
+	})
+
+	if err := stm.UpdateContext(context.Background(), 0, "where should we eat tonight?", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	items := stm.Items()
+	sim := CosineSimilarity(e.vecs["dinner plans"], e.vecs["where should we eat tonight?"])
+	if want := 0.5 + config.RefreshBoost*sim; items[0].Activation < want-1e-6 || items[0].Activation > want+1e-6 {
+		t.Errorf("paraphrase should refresh by boost × similarity: got %f, want %f", items[0].Activation, want)
+	}
+	if items[1].Activation > 0.5 {
+		t.Errorf("unrelated item should not be refreshed: %f", items[1].Activation)
+	}
+	if items[0].TopicEmbedding == nil || items[1].TopicEmbedding == nil {
+		t.Error("topic embeddings should be cached on the items")
+	}
+
+	// Keyword matches keep the full boost; topics are not embedded again.
+	if err := stm.UpdateContext(context.Background(), 0, "the budget is tight", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := stm.Items()[1].Activation; got < 0.8-1e-6 {
+		t.Errorf("keyword match should get the full boost, got %f", got)
+	}
+	if e.calls["dinner plans"] != 1 || e.calls["budget"] != 1 {
+		t.Errorf("topic embeddings should be cached: %v", e.calls)
+	}
+}
+
+func TestSTM_UpdateContextErrorLeavesSTMUnchanged(t *testing.T) {
+	e := newConceptEmbedder()
+	e.err = errors.New("rate limited")
+	config := DefaultSTMConfig()
+	config.EmbeddingFunc = e.embed
+	stm := NewSTM(config)
+	stm.SetItems([]*WorkingMemoryItem{{Topic: "dinner plans", Keywords: []string{"dinner"}, Activation: 0.5}})
+
+	err := stm.UpdateContext(context.Background(), 3, "where should we eat tonight?", nil)
+	if err == nil || !errors.Is(err, e.err) {
+		t.Fatalf("expected the embedding error, got %v", err)
+	}
+	if got := stm.Items()[0]; got.Activation != 0.5 || stm.Turn() != 0 {
+		t.Errorf("STM should be unchanged on error: %+v turn %d", got, stm.Turn())
+	}
+}
+
+func TestSTM_UpdateContextWithoutEmbedding(t *testing.T) {
+	stm := NewSTM(DefaultSTMConfig())
+	stm.SetItems([]*WorkingMemoryItem{{Topic: "dinner plans", Keywords: []string{"dinner"}, Activation: 0.5}})
+	if err := stm.UpdateContext(context.Background(), 0, "dinner at eight", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := stm.Items()[0]; got.Activation <= 0.5 || got.TopicEmbedding != nil {
+		t.Errorf("without EmbeddingFunc UpdateContext should be Update: %+v", got)
+	}
+}
+
+func TestSTM_UpdateContextEmbedsOnlyWhenNeeded(t *testing.T) {
+	e := newConceptEmbedder()
+	config := DefaultSTMConfig()
+	config.EmbeddingFunc = e.embed
+	stm := NewSTM(config)
+	total := func() int {
+		n := 0
+		for _, c := range e.calls {
+			n += c
+		}
+		return n
+	}
+
+	for turn := range 3 {
+		if err := stm.UpdateContext(context.Background(), turn, "hello", nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if n := total(); n != 0 {
+		t.Errorf("an empty STM needs no embeddings, got %d calls", n)
+	}
+
+	stm.SetItems([]*WorkingMemoryItem{
+		{Topic: "dinner plans", Keywords: []string{"dinner"}, Activation: 0.5},
+		{Topic: "budget", Keywords: []string{"budget"}, Activation: 0.5},
+	})
+	if err := stm.UpdateContext(context.Background(), 3, "dinner on a budget", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := total(); n != 0 {
+		t.Errorf("keyword matches for every item need no embeddings, got %d calls", n)
+	}
+
+	if err := stm.UpdateContext(context.Background(), 4, "the budget again", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e.calls["the budget again"] != 1 || e.calls["dinner plans"] != 1 || e.calls["budget"] != 0 {
+		t.Errorf("only the message and the unmatched topic should be embedded: %v", e.calls)
+	}
+}
@@ -0,0 +1,173 @@
+package memai
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// STMSnapshotVersion is the version of the STM snapshot format. Decoding
+// accepts this version and older ones.
+const STMSnapshotVersion = 1
+
+// STMSnapshot is a self-contained copy of an STM's state: its configuration,
+// the latest turn it processed and its items.
+//
+// Plugin fields of STMConfig (TopicExtractor, KeywordMatcher, Clock,
+// DecayFunc, EmbeddingFunc) are kept in memory but not
+// encoded. Decoding into an existing STM keeps its plugins; otherwise set
+// them on Config before NewSTMFromSnapshot.
+type STMSnapshot struct {
+	Version int                 `json:"version"`
+	Turn    int                 `json:"turn"`
+	Config  STMConfig           `json:"config"`
+	Items   []WorkingMemoryItem `json:"items"`
+}
+
+// Snapshot returns a deep copy of the STM's state.
+func (s *STM) Snapshot() STMSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := make([]WorkingMemoryItem, len(s.items))
+	for i, it := range s.items {
+		items[i] = cloneItem(it)
+	}
+	return STMSnapshot{Version: STMSnapshotVersion, Turn: s.turn, Config: s.config, Items: items}
+}
+
+// NewSTMFromSnapshot returns a new STM with the state of snap. The STM does
+// not share memory with snap.
+func NewSTMFromSnapshot(snap STMSnapshot) (*STM, error) {
+	stm := NewSTM(snap.Config)
+	if err := stm.restore(snap); err != nil {
+		return nil, err
+	}
+	return stm, nil
+}
+
+// restore replaces the state of s with snap after checking its version.
+// The configuration is normalised as in NewSTM.
+func (s *STM) restore(snap STMSnapshot) error {
+	if snap.Version < 1 || snap.Version > STMSnapshotVersion {
+		return fmt.Errorf("%w: STM snapshot version %d", ErrCheckpointFormat, snap.Version)
+	}
+	items := make([]*WorkingMemoryItem, len(snap.Items))
+	for i := range snap.Items {
+		it := cloneItem(&snap.Items[i])
+		items[i] = &it
+	}
+	config := normalizeSTMConfig(snap.Config)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	config.keepPlugins(s.config)
+	s.config, s.turn, s.items = config, snap.Turn, items
+	return nil
+}
+
+// MarshalJSON encodes the STM as its snapshot.
+func (s *STM) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Snapshot())
+}
+
+// UnmarshalJSON replaces the STM's state with a JSON snapshot.
+func (s *STM) UnmarshalJSON(data []byte) error {
+	var snap STMSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return err
+	}
+	return s.restore(snap)
+}
+
+// GobEncode encodes the STM as its snapshot.
+func (s *STM) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	snap := s.Snapshot()
+	snap.Config = snap.Config.withoutPlugins()
+	if err := gob.NewEncoder(&buf).Encode(snap); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode replaces the STM's state with a gob-encoded snapshot.
+func (s *STM) GobDecode(data []byte) error {
+	var snap STMSnapshot
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&snap); err != nil {
+		return err
+	}
+	return s.restore(snap)
+}
+
+// WriteTo writes the STM to w in the versioned on-disk format: the JSON
+// snapshot followed by a newline. It implements io.WriterTo.
+func (s *STM) WriteTo(w io.Writer) (int64, error) {
+	data, err := s.MarshalJSON()
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(append(data, '\n'))
+	return int64(n), err
+}
+
+// ReadSTM reads an STM written by STM.WriteTo.
+func ReadSTM(r io.Reader) (*STM, error) {
+	var snap STMSnapshot
+	if err := json.NewDecoder(r).Decode(&snap); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCheckpointFormat, err)
+	}
+	return NewSTMFromSnapshot(snap)
+}
+
+// withoutPlugins returns c with its plugin fields cleared, for encodings
+// (gob) that cannot carry them.
+func (c STMConfig) withoutPlugins() STMConfig {
+	c.TopicExtractor = nil
+	c.KeywordMatcher = nil
+	c.Clock = nil
+	c.DecayFunc = nil
+	c.EmbeddingFunc = nil
+	return c
+}
+
+// keepPlugins fills the plugin fields c lacks from prev.
+func (c *STMConfig) keepPlugins(prev STMConfig) {
+	if c.TopicExtractor == nil {
+		c.TopicExtractor = prev.TopicExtractor
+	}
+	if c.KeywordMatcher == nil {
+		c.KeywordMatcher = prev.KeywordMatcher
+	}
+	if c.Clock == nil {
+		c.Clock = prev.Clock
+	}
+	if c.DecayFunc == nil {
+		c.DecayFunc = prev.DecayFunc
+	}
+	if c.EmbeddingFunc == nil {
+		c.EmbeddingFunc = prev.EmbeddingFunc
+	}
+}
+
+// cloneItems deep-copies working memory items. nil entries are dropped.
+func cloneItems(items []*WorkingMemoryItem) []*WorkingMemoryItem {
+	out := make([]*WorkingMemoryItem, 0, len(items))
+	for _, it := range items {
+		if it == nil {
+			continue
+		}
+		c := cloneItem(it)
+		out = append(out, &c)
+	}
+	return out
+}
+
+// cloneItem returns a copy of it that shares no memory with it.
+func cloneItem(it *WorkingMemoryItem) WorkingMemoryItem {
+	c := *it
+	c.Keywords = append([]string(nil), it.Keywords...)
+	c.TopicEmbedding = slices.Clone(it.TopicEmbedding)
+	return c
+}
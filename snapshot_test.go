@@ -0,0 +1,108 @@
+package memai
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSTM_ItemsAndSetItemsCopy(t *testing.T) {
+	item := &WorkingMemoryItem{Topic: "A", Keywords: []string{"a"}, Activation: 0.5}
+	stm := NewSTM(DefaultSTMConfig())
+	stm.SetItems([]*WorkingMemoryItem{item})
+	item.Activation = 0.9
+	item.Keywords[0] = "changed"
+
+	got := stm.Items()
+	if got[0].Activation != 0.5 || got[0].Keywords[0] != "a" {
+		t.Errorf("SetItems should copy the caller's items: %+v", got[0])
+	}
+	got[0].Activation = 0
+	got[0].Keywords[0] = "changed"
+	if again := stm.Items(); again[0].Activation != 0.5 || again[0].Keywords[0] != "a" {
+		t.Errorf("Items should return copies: %+v", again[0])
+	}
+}
+
+func TestSTM_JSONRoundTrip(t *testing.T) {
+	stm, _ := checkpointFixture()
+	data, err := json.Marshal(stm)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(data), `"version":1`) || !strings.Contains(string(data), `"turn":3`) {
+		t.Errorf("unexpected JSON: %s", data)
+	}
+	restored := NewSTM(STMConfig{})
+	if err := json.Unmarshal(data, restored); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(restored.Snapshot(), stm.Snapshot()) {
+		t.Errorf("round trip differs:\n got %+v\nwant %+v", restored.Snapshot(), stm.Snapshot())
+	}
+}
+
+func TestSTM_GobRoundTrip(t *testing.T) {
+	type session struct {
+		ID  string
+		STM *STM
+	}
+	stm, _ := checkpointFixture()
+	in := session{ID: "c1", STM: stm}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(in); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var out session
+	if err := gob.NewDecoder(&buf).Decode(&out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(out.STM.Snapshot(), in.STM.Snapshot()) {
+		t.Errorf("round trip differs:\n got %+v\nwant %+v", out.STM.Snapshot(), in.STM.Snapshot())
+	}
+}
+
+func TestSTM_WriteToReadSTM(t *testing.T) {
+	stm, _ := checkpointFixture()
+	var buf bytes.Buffer
+	if _, err := stm.WriteTo(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	restored, err := ReadSTM(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if restored.Turn() != 3 || len(restored.Items()) != 2 {
+		t.Errorf("unexpected restored STM: turn %d, %d items", restored.Turn(), len(restored.Items()))
+	}
+
+	if _, err := ReadSTM(strings.NewReader(`{"version":2,"items":[]}`)); !errors.Is(err, ErrCheckpointFormat) {
+		t.Errorf("expected ErrCheckpointFormat for a newer version, got %v", err)
+	}
+}
+
+func TestSTM_PluginsNotEncoded(t *testing.T) {
+	config := DefaultSTMConfig()
+	config.TopicExtractor = fixedTopics{{Topic: "x", Keywords: []string{"x"}}}
+	stm := NewSTM(config)
+	stm.Ingest(1, "x", nil)
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(stm); err != nil {
+		t.Fatalf("gob should skip plugins: %v", err)
+	}
+	restored := NewSTM(config)
+	if err := gob.NewDecoder(&buf).Decode(restored); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if restored.Config().TopicExtractor == nil {
+		t.Error("decoding into an STM should keep its plugins")
+	}
+	if _, err := NewCheckpoint[int](stm, nil, nil).MarshalBinary(); err != nil {
+		t.Errorf("checkpoint should skip plugins: %v", err)
+	}
+}
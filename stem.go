@@ -0,0 +1,230 @@
+package memai
+
+import "strings"
+
+// StemEnglish reduces a lowercase English word to its stem with the Porter
+// (1980) algorithm, so that inflected forms share a stem: "meetings",
+// "meeting" and "meet" all become "meet". Words of one or two letters are
+// returned unchanged.
+func StemEnglish(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+	w := []byte(word)
+	for _, c := range w {
+		if c < 'a' || c > 'z' {
+			return word
+		}
+	}
+	w = porterStep1a(w)
+	w = porterStep1b(w)
+	w = porterStep1c(w)
+	w = porterStep2(w)
+	w = porterStep3(w)
+	w = porterStep4(w)
+	w = porterStep5(w)
+	return string(w)
+}
+
+// isConsonant reports whether w[i] is a consonant in Porter's sense: not a
+// vowel, and y only after a vowel.
+func isConsonant(w []byte, i int) bool {
+	switch w[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !isConsonant(w, i-1)
+	}
+	return true
+}
+
+// measure returns m, the number of vowel-consonant sequences in w.
+func measure(w []byte) int {
+	m, i := 0, 0
+	for i < len(w) && isConsonant(w, i) {
+		i++
+	}
+	for i < len(w) {
+		for i < len(w) && !isConsonant(w, i) {
+			i++
+		}
+		if i == len(w) {
+			break
+		}
+		for i < len(w) && isConsonant(w, i) {
+			i++
+		}
+		m++
+	}
+	return m
+}
+
+func hasVowel(w []byte) bool {
+	for i := range w {
+		if !isConsonant(w, i) {
+			return true
+		}
+	}
+	return false
+}
+
+// endsDoubleConsonant reports *d: w ends with two equal consonants.
+func endsDoubleConsonant(w []byte) bool {
+	n := len(w)
+	return n >= 2 && w[n-1] == w[n-2] && isConsonant(w, n-1)
+}
+
+// endsCVC reports *o: w ends consonant-vowel-consonant, the last not w, x
+// or y.
+func endsCVC(w []byte) bool {
+	n := len(w)
+	if n < 3 || !isConsonant(w, n-3) || isConsonant(w, n-2) || !isConsonant(w, n-1) {
+		return false
+	}
+	c := w[n-1]
+	return c != 'w' && c != 'x' && c != 'y'
+}
+
+// replaceSuffix replaces suffix with repl when the stem before it has a
+// measure above minM. matched reports whether w ends with suffix at all.
+func replaceSuffix(w []byte, suffix, repl string, minM int) (out []byte, matched bool) {
+	if !strings.HasSuffix(string(w), suffix) {
+		return w, false
+	}
+	stem := w[:len(w)-len(suffix)]
+	if measure(stem) > minM {
+		return append(stem[:len(stem):len(stem)], repl...), true
+	}
+	return w, true
+}
+
+func porterStep1a(w []byte) []byte {
+	s := string(w)
+	switch {
+	case strings.HasSuffix(s, "sses"), strings.HasSuffix(s, "ies"):
+		return w[:len(w)-2]
+	case strings.HasSuffix(s, "ss"):
+		return w
+	case strings.HasSuffix(s, "s"):
+		return w[:len(w)-1]
+	}
+	return w
+}
+
+func porterStep1b(w []byte) []byte {
+	s := string(w)
+	if strings.HasSuffix(s, "eed") {
+		if measure(w[:len(w)-3]) > 0 {
+			return w[:len(w)-1]
+		}
+		return w
+	}
+	var stem []byte
+	switch {
+	case strings.HasSuffix(s, "ed") && hasVowel(w[:len(w)-2]):
+		stem = w[:len(w)-2]
+	case strings.HasSuffix(s, "ing") && hasVowel(w[:len(w)-3]):
+		stem = w[:len(w)-3]
+	default:
+		return w
+	}
+	t := string(stem)
+	switch {
+	case strings.HasSuffix(t, "at"), strings.HasSuffix(t, "bl"), strings.HasSuffix(t, "iz"):
+		return append(stem[:len(stem):len(stem)], 'e')
+	case endsDoubleConsonant(stem):
+		if c := stem[len(stem)-1]; c != 'l' && c != 's' && c != 'z' {
+			return stem[:len(stem)-1]
+		}
+	case measure(stem) == 1 && endsCVC(stem):
+		return append(stem[:len(stem):len(stem)], 'e')
+	}
+	return stem
+}
+
+func porterStep1c(w []byte) []byte {
+	if n := len(w); n > 1 && w[n-1] == 'y' && hasVowel(w[:n-1]) {
+		out := append(w[:n-1:n-1], 'i')
+		return out
+	}
+	return w
+}
+
+// porterStep2Suffixes are the step 2 replacements, tried in order.
+var porterStep2Suffixes = [][2]string{
+	{"ational", "ate"}, {"tional", "tion"}, {"enci", "ence"}, {"anci", "ance"},
+	{"izer", "ize"}, {"abli", "able"}, {"alli", "al"}, {"entli", "ent"},
+	{"eli", "e"}, {"ousli", "ous"}, {"ization", "ize"}, {"ation", "ate"},
+	{"ator", "ate"}, {"alism", "al"}, {"iveness", "ive"}, {"fulness", "ful"},
+	{"ousness", "ous"}, {"aliti", "al"}, {"iviti", "ive"}, {"biliti", "ble"},
+}
+
+func porterStep2(w []byte) []byte {
+	for _, r := range porterStep2Suffixes {
+		if out, ok := replaceSuffix(w, r[0], r[1], 0); ok {
+			return out
+		}
+	}
+	return w
+}
+
+// porterStep3Suffixes are the step 3 replacements, tried in order.
+var porterStep3Suffixes = [][2]string{
+	{"icate", "ic"}, {"ative", ""}, {"alize", "al"}, {"iciti", "ic"},
+	{"ical", "ic"}, {"ful", ""}, {"ness", ""},
+}
+
+func porterStep3(w []byte) []byte {
+	for _, r := range porterStep3Suffixes {
+		if out, ok := replaceSuffix(w, r[0], r[1], 0); ok {
+			return out
+		}
+	}
+	return w
+}
+
+// porterStep4Suffixes are removed in step 4 when m > 1.
+var porterStep4Suffixes = []string{
+	"al", "ance", "ence", "er", "ic", "able", "ible", "ant", "ement", "ment",
+	"ent", "ion", "ou", "ism", "ate", "iti", "ous", "ive", "ize",
+}
+
+func porterStep4(w []byte) []byte {
+	s := string(w)
+	// Longer suffixes first, so "ement" wins over "ment" and "ent".
+	best := ""
+	for _, suf := range porterStep4Suffixes {
+		if len(suf) > len(best) && strings.HasSuffix(s, suf) {
+			best = suf
+		}
+	}
+	if best == "" {
+		return w
+	}
+	stem := w[:len(w)-len(best)]
+	if measure(stem) <= 1 {
+		return w
+	}
+	if best == "ion" {
+		if n := len(stem); n == 0 || (stem[n-1] != 's' && stem[n-1] != 't') {
+			return w
+		}
+	}
+	return stem
+}
+
+func porterStep5(w []byte) []byte {
+	if len(w) == 0 {
+		return w
+	}
+	if n := len(w); w[n-1] == 'e' {
+		stem := w[:n-1]
+		if m := measure(stem); m > 1 || m == 1 && !endsCVC(stem) {
+			w = stem
+		}
+	}
+	if n := len(w); w[n-1] == 'l' && endsDoubleConsonant(w) && measure(w) > 1 {
+		w = w[:n-1]
+	}
+	return w
+}
@@ -0,0 +1,36 @@
+package memai
+
+import "testing"
+
+func TestStemEnglish(t *testing.T) {
+	tests := map[string]string{
+		"caresses":       "caress",
+		"ponies":         "poni",
+		"cats":           "cat",
+		"meeting":        "meet",
+		"meetings":       "meet",
+		"agreed":         "agre",
+		"plastered":      "plaster",
+		"motoring":       "motor",
+		"sing":           "sing",
+		"hopping":        "hop",
+		"filing":         "file",
+		"happy":          "happi",
+		"relational":     "relat",
+		"conditional":    "condit",
+		"generalization": "gener",
+		"hopeful":        "hope",
+		"goodness":       "good",
+		"adjustment":     "adjust",
+		"adoption":       "adopt",
+		"controll":       "control",
+		"rate":           "rate",
+		"go":             "go",
+		"café":           "café",
+	}
+	for in, want := range tests {
+		if got := StemEnglish(in); got != want {
+			t.Errorf("StemEnglish(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
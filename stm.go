@@ -1,18 +1,50 @@
 package memai
 
 import (
+	"math"
 	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
 // STMConfig configures short-term memory behavior.
 type STMConfig struct {
-	MaxItems            int     // Maximum working memory capacity (default: 7)
-	ActivationThreshold float64 // Below this, items are evicted (default: 0.1)
-	NormalDecayRate     float64 // Activation decay per turn (default: 0.15)
-	EmotionalDecayRate  float64 // Decay for emotional items (default: 0.07)
-	RefreshBoost        float64 // Activation boost on keyword match (default: 0.3)
+	MaxItems            int     `json:"max_items"`            // Maximum working memory capacity (default: 7)
+	ActivationThreshold float64 `json:"activation_threshold"` // Below this, items are evicted (default: 0.1)
+	NormalDecayRate     float64 `json:"normal_decay_rate"`    // Activation decay per turn (default: 0.15)
+	EmotionalDecayRate  float64 `json:"emotional_decay_rate"` // Decay for emotional items (default: 0.07)
+	RefreshBoost        float64 `json:"refresh_boost"`        // Activation boost on keyword match (default: 0.3)
+	// Time-based decay, added to the per-turn decay, so working memory fades
+	// over a paused conversation. Off by default; 0.02 and 0.01 make a
+	// three-day pause forget ordinary items but keep emotional ones.
+	// Wall-clock time accumulates until a minute has passed, then the exact
+	// elapsed time is charged.
+	TimeDecayRate          float64 `json:"time_decay_rate"`           // Activation decay per hour (default: 0)
+	EmotionalTimeDecayRate float64 `json:"emotional_time_decay_rate"` // Per-hour decay for emotional items (default: 0)
+	// Language selects the keyword matcher when KeywordMatcher is nil:
+	// LangEnglish matches whole words with stemming, LangJapanese uses
+	// JapaneseMatcher (default: "", case-insensitive substring match).
+	Language Language `json:"language,omitempty"`
+	// SemanticThreshold is the cosine similarity between a message and an
+	// item's topic above which UpdateContext refreshes the item (default: 0.75).
+	SemanticThreshold float64 `json:"semantic_threshold"`
+
+	// TopicExtractor finds new topics for Ingest (default:
+	// HeuristicTopicExtractor). Like the other plugin fields it is not part
+	// of snapshots and checkpoints; see STMSnapshot.
+	TopicExtractor TopicExtractor `json:"-"`
+	// KeywordMatcher decides whether a message mentions an item's keywords,
+	// overriding Language (default: nil).
+	KeywordMatcher KeywordMatcher `json:"-"`
+	// Clock is the time source of Update and Ingest (default: time.Now).
+	Clock func() time.Time `json:"-"`
+	// DecayFunc shapes forgetting (default: LinearDecay). See also
+	// ExponentialDecay, PowerLawDecay and ACTRDecay.
+	DecayFunc DecayFunc `json:"-"`
+	// EmbeddingFunc enables semantic refresh in UpdateContext (default: nil,
+	// keyword matching only).
+	EmbeddingFunc EmbeddingFunc `json:"-"`
 }
 
 // DefaultSTMConfig returns the default STM configuration based on
@@ -24,6 +56,7 @@ func DefaultSTMConfig() STMConfig {
 		NormalDecayRate:     0.15,
 		EmotionalDecayRate:  0.07,
 		RefreshBoost:        0.3,
+		SemanticThreshold:   0.75,
 	}
 }
 
@@ -36,6 +69,9 @@ type STM struct {
 	mu     sync.Mutex
 	config STMConfig
 	items  []*WorkingMemoryItem
+	turn   int // Latest turn passed to Update
+	hooks  STMHooks
+	events []stmEvent // Recorded for hooks, delivered by unlock
 }
 
 // NewSTM creates a new short-term memory manager. Non-positive MaxItems and
@@ -43,6 +79,11 @@ type STM struct {
 // values so that a zero-value or partially-filled config cannot silently
 // wipe working memory or invert decay.
 func NewSTM(config STMConfig) *STM {
+	return &STM{config: normalizeSTMConfig(config)}
+}
+
+// normalizeSTMConfig replaces out-of-range fields with their defaults.
+func normalizeSTMConfig(config STMConfig) STMConfig {
 	d := DefaultSTMConfig()
 	if config.MaxItems <= 0 {
 		config.MaxItems = d.MaxItems
@@ -59,64 +100,226 @@ func NewSTM(config STMConfig) *STM {
 	if config.RefreshBoost < 0 {
 		config.RefreshBoost = d.RefreshBoost
 	}
-	return &STM{config: config}
+	if config.TimeDecayRate < 0 {
+		config.TimeDecayRate = d.TimeDecayRate
+	}
+	if config.EmotionalTimeDecayRate < 0 {
+		config.EmotionalTimeDecayRate = d.EmotionalTimeDecayRate
+	}
+	// A threshold of 0 would refresh every item on every message.
+	if config.SemanticThreshold <= 0 {
+		config.SemanticThreshold = d.SemanticThreshold
+	}
+	return config
 }
 
-// Items returns a snapshot of the current working memory items. The returned
-// slice is a copy, so appending to it does not affect internal state (the
-// pointed-to items are shared).
+// Items returns a deep copy of the current working memory items. Changing
+// the returned items does not affect the STM; use SetItems to write back.
 func (s *STM) Items() []*WorkingMemoryItem {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	out := make([]*WorkingMemoryItem, len(s.items))
-	copy(out, s.items)
-	return out
+	return cloneItems(s.items)
 }
 
-// SetItems replaces the working memory contents.
+// SetItems replaces the working memory contents with a deep copy of items.
 func (s *STM) SetItems(items []*WorkingMemoryItem) {
+	items = cloneItems(items)
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.items = items
 }
 
+// Config returns the configuration in effect.
+func (s *STM) Config() STMConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.config
+}
+
+// Turn returns the latest turn processed by Update (0 before the first).
+func (s *STM) Turn() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.turn
+}
+
 // Update performs a full STM cycle: decay, emotional marking, refresh, eviction.
+// Decay covers the turns and the time (per the configured Clock) since each
+// item was last decayed.
 func (s *STM) Update(turn int, message string, emotion *EmotionalState) {
+	s.UpdateAt(turn, s.now(), message, emotion)
+}
+
+// UpdateAt is Update at the given time, for replaying a conversation from
+// its message timestamps.
+func (s *STM) UpdateAt(turn int, now time.Time, message string, emotion *EmotionalState) {
+	// Store UTC without a monotonic reading, so times survive encoding
+	// unchanged.
+	now = now.Round(0).UTC()
 	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.decay(turn)
+	defer s.unlock()
+	s.turn = max(s.turn, turn)
+	s.decay(turn, now)
 	s.markEmotional(message, emotion)
 	s.refresh(message)
 	s.evict()
 }
 
-// Add inserts a new item into working memory, evicting the lowest-activation
-// item if capacity is exceeded.
+// Ingest performs a full STM cycle like Update and then adds the topics the
+// message introduces. Candidates come from the configured TopicExtractor;
+// those already covered by an item in working memory (which the refresh
+// step has just boosted) are skipped, and the rest are added with full
+// activation, marked emotional if the message carries emotion. The added
+// items are returned.
+func (s *STM) Ingest(turn int, message string, emotion *EmotionalState) []*WorkingMemoryItem {
+	s.mu.Lock()
+	extractor := s.config.TopicExtractor
+	s.mu.Unlock()
+	if extractor == nil {
+		extractor = NewHeuristicTopicExtractor()
+	}
+	// Extract without holding the lock; extractors may call a model.
+	candidates := extractor.ExtractTopics(message)
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.unlock()
+	s.turn = max(s.turn, turn)
+	s.decay(turn, now)
+	s.markEmotional(message, emotion)
+	s.refresh(message)
+
+	var added []*WorkingMemoryItem
+	for _, c := range candidates {
+		if len(c.Keywords) == 0 || s.covers(c) {
+			continue
+		}
+		item := cloneItem(&c)
+		item.Activation = 1.0
+		item.TurnCreated, item.TurnAccessed = turn, turn
+		item.LastAccessed = now
+		primeDecayState(&item)
+		item.Emotional = emotion != nil && emotion.Intensity > 0.3
+		s.items = append(s.items, &item)
+		added = append(added, &item)
+		s.emit(eventAdd, &item, "", 0)
+	}
+	s.evict()
+
+	// Report only the items that survived eviction.
+	out := make([]*WorkingMemoryItem, 0, len(added))
+	for _, a := range added {
+		for _, it := range s.items {
+			if it == a {
+				c := cloneItem(a)
+				out = append(out, &c)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// covers reports whether an item in working memory already holds the topic
+// of candidate: one of its keywords appears in the candidate's topic or
+// keywords.
+func (s *STM) covers(candidate WorkingMemoryItem) bool {
+	text := candidate.Topic + " " + strings.Join(candidate.Keywords, " ")
+	for _, item := range s.items {
+		if strings.EqualFold(item.Topic, candidate.Topic) || s.itemMatchesMessage(item, text) {
+			return true
+		}
+	}
+	return false
+}
+
+// Add inserts a copy of item into working memory, evicting the
+// lowest-activation item if capacity is exceeded.
 func (s *STM) Add(item *WorkingMemoryItem) {
+	c := cloneItem(item)
+	if c.LastAccessed.IsZero() {
+		c.LastAccessed = s.now()
+	}
+	primeDecayState(&c)
 	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.items = append(s.items, item)
+	defer s.unlock()
+	s.items = append(s.items, &c)
+	s.emit(eventAdd, &c, "", 0)
 	s.evict()
 }
 
-// decay reduces activation of all items based on elapsed turns.
-func (s *STM) decay(currentTurn int) {
+// now returns the current time from the configured Clock, in UTC and
+// without a monotonic reading.
+func (s *STM) now() time.Time {
+	s.mu.Lock()
+	clock := s.config.Clock
+	s.mu.Unlock()
+	if clock != nil {
+		return clock().Round(0).UTC()
+	}
+	return time.Now().Round(0).UTC()
+}
+
+// Time decay granularity and the smallest activation change reported to
+// OnDecay. Without them, several Updates in one turn would each charge a few
+// microseconds of decay and report it.
+const (
+	minDecayInterval = time.Minute
+	minDecayDelta    = 1e-9
+)
+
+// decay reduces activation of all items based on elapsed turns and time,
+// using the configured DecayFunc. An item without a LastAccessed time starts
+// its clock now. Time is only charged once at least minDecayInterval has
+// passed, so shorter intervals accumulate instead of being lost.
+func (s *STM) decay(currentTurn int, now time.Time) {
+	decayFn := s.config.DecayFunc
+	if decayFn == nil {
+		decayFn = LinearDecay
+	}
 	for _, item := range s.items {
-		elapsed := currentTurn - item.TurnAccessed
-		if elapsed <= 0 {
+		elapsed := max(currentTurn-item.TurnAccessed, 0)
+		var hours float64
+		if !item.LastAccessed.IsZero() && now.Sub(item.LastAccessed) >= minDecayInterval {
+			hours = now.Sub(item.LastAccessed).Hours()
+		}
+		if item.LastAccessed.IsZero() || hours > 0 {
+			item.LastAccessed = now
+		}
+		if elapsed == 0 && hours == 0 {
 			continue
 		}
 
-		rate := s.config.NormalDecayRate
+		rate, timeRate := s.config.NormalDecayRate, s.config.TimeDecayRate
 		if item.Emotional {
-			rate = s.config.EmotionalDecayRate
+			rate, timeRate = s.config.EmotionalDecayRate, s.config.EmotionalTimeDecayRate
 		}
 
-		item.Activation -= rate * float64(elapsed)
-		if item.Activation < 0 {
+		item.TurnAccessed = max(item.TurnAccessed, currentTurn)
+		load := rate*float64(elapsed) + timeRate*hours
+		if load <= 0 {
+			continue
+		}
+
+		primeDecayState(item)
+		item.Age += load
+		item.Lifetime += load
+		before := item.Activation
+		item.Activation = decayFn(DecayInput{
+			Activation:    item.Activation,
+			Strength:      item.Strength,
+			Elapsed:       load,
+			Age:           item.Age,
+			Lifetime:      item.Lifetime,
+			Presentations: item.Presentations,
+			Emotional:     item.Emotional,
+		})
+		if !(item.Activation > 0) { // also catches NaN
 			item.Activation = 0
 		}
-		item.TurnAccessed = currentTurn
+		if math.Abs(item.Activation-before) > minDecayDelta {
+			s.emit(eventDecay, item, "", before)
+		}
 	}
 }
 
@@ -129,27 +332,49 @@ func (s *STM) markEmotional(message string, emotion *EmotionalState) {
 	if emotion == nil || emotion.Intensity <= 0.3 {
 		return
 	}
-	lower := strings.ToLower(message)
 	for _, item := range s.items {
-		if itemMatchesMessage(item, lower) {
+		if !item.Emotional && s.itemMatchesMessage(item, message) {
 			item.Emotional = true
+			s.emit(eventMarkedEmotional, item, "", 0)
 		}
 	}
 }
 
 // refresh boosts activation of items whose keywords match the message.
 func (s *STM) refresh(message string) {
-	lower := strings.ToLower(message)
+	s.refreshSemantic(message, nil)
+}
+
+// refreshSemantic boosts items whose keywords match the message by
+// RefreshBoost and, when msgVec is set, items whose cached topic embedding is
+// at least SemanticThreshold similar to it by RefreshBoost scaled by the
+// similarity. Keyword matches take precedence.
+func (s *STM) refreshSemantic(message string, msgVec []float64) {
 	for _, item := range s.items {
-		if itemMatchesMessage(item, lower) {
-			item.Activation += s.config.RefreshBoost
-			if item.Activation > 1.0 {
-				item.Activation = 1.0
+		switch {
+		case s.itemMatchesMessage(item, message):
+			s.boost(item, s.config.RefreshBoost)
+		case msgVec != nil:
+			if sim := CosineSimilarity(item.TopicEmbedding, msgVec); sim >= s.config.SemanticThreshold {
+				s.boost(item, s.config.RefreshBoost*sim)
 			}
 		}
 	}
 }
 
+// boost raises an item's activation by amount, capped at 1, and counts it as
+// a presentation for the DecayFunc.
+func (s *STM) boost(item *WorkingMemoryItem, amount float64) {
+	item.Activation += amount
+	if item.Activation > 1.0 {
+		item.Activation = 1.0
+	}
+	primeDecayState(item)
+	item.Presentations++
+	item.Strength, item.Age = item.Activation, 0
+	s.emit(eventRefresh, item, "", 0)
+}
+
 // evict removes low-activation items and enforces capacity.
 func (s *STM) evict() {
 	// Remove below threshold
@@ -157,6 +382,8 @@ func (s *STM) evict() {
 	for _, item := range s.items {
 		if item.Activation >= s.config.ActivationThreshold {
 			alive = append(alive, item)
+		} else {
+			s.emit(eventEvict, item, EvictThreshold, 0)
 		}
 	}
 	s.items = alive
@@ -166,16 +393,18 @@ func (s *STM) evict() {
 		sort.SliceStable(s.items, func(i, j int) bool {
 			return s.items[i].Activation > s.items[j].Activation
 		})
+		for _, item := range s.items[s.config.MaxItems:] {
+			s.emit(eventEvict, item, EvictCapacity, 0)
+		}
 		s.items = s.items[:s.config.MaxItems]
 	}
 }
 
-// itemMatchesMessage checks if any of the item's keywords appear in the message.
-func itemMatchesMessage(item *WorkingMemoryItem, lowerMsg string) bool {
-	for _, kw := range item.Keywords {
-		if strings.Contains(lowerMsg, strings.ToLower(kw)) {
-			return true
-		}
+// itemMatchesMessage checks if any of the item's keywords appear in the
+// message, using the matcher selected by the configuration.
+func (s *STM) itemMatchesMessage(item *WorkingMemoryItem, message string) bool {
+	if len(item.Keywords) == 0 {
+		return false
 	}
-	return false
+	return matcherFor(s.config).MatchKeywords(message, item.Keywords)
 }
@@ -1,8 +1,10 @@
 package memai
 
 import (
+	"math"
 	"sync"
 	"testing"
+	"time"
 )
 
 func TestSTM_Decay(t *testing.T) {
@@ -160,3 +162,132 @@ func TestSTM_ConcurrentAccess(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+func TestSTM_IngestAddsAndRefreshes(t *testing.T) {
+	stm := NewSTM(DefaultSTMConfig())
+	stm.SetItems([]*WorkingMemoryItem{
+		{Topic: "旅行", Keywords: []string{"京都"}, Activation: 0.5},
+	})
+
+	added := stm.Ingest(1, "京都にまた行きたい！", &EmotionalState{Primary: EmotionJoy, Intensity: 0.6})
+	if len(added) != 0 {
+		t.Errorf("topic already in working memory should not be added again: %+v", added)
+	}
+	items := stm.Items()
+	if len(items) != 1 || items[0].Activation <= 0.5 || !items[0].Emotional {
+		t.Fatalf("existing item should be refreshed and marked emotional: %+v", items)
+	}
+
+	added = stm.Ingest(2, "来週のプロジェクト会議はどうなった？", nil)
+	if len(added) != 1 || added[0].Topic != "プロジェクト会議" {
+		t.Fatalf("expected the new topic to be added, got %+v", added)
+	}
+	if added[0].Activation != 1.0 || added[0].TurnCreated != 2 || added[0].Emotional {
+		t.Errorf("unexpected new item: %+v", added[0])
+	}
+	if len(stm.Items()) != 2 || stm.Turn() != 2 {
+		t.Errorf("expected 2 items at turn 2, got %d at turn %d", len(stm.Items()), stm.Turn())
+	}
+}
+
+type fixedTopics []WorkingMemoryItem
+
+func (f fixedTopics) ExtractTopics(string) []WorkingMemoryItem { return f }
+
+func TestSTM_IngestUsesConfiguredExtractor(t *testing.T) {
+	config := DefaultSTMConfig()
+	config.TopicExtractor = fixedTopics{
+		{Topic: "dinner plans", Keywords: []string{"dinner"}},
+		{Topic: "no keywords"},
+	}
+	stm := NewSTM(config)
+	added := stm.Ingest(1, "anything", nil)
+	if len(added) != 1 || added[0].Topic != "dinner plans" {
+		t.Errorf("expected only the candidate with keywords, got %+v", added)
+	}
+}
+
+func TestSTM_TimeDecay(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	config := DefaultSTMConfig()
+	config.TimeDecayRate = 0.02
+	config.EmotionalTimeDecayRate = 0.01
+	config.Clock = func() time.Time { return now }
+	stm := NewSTM(config)
+	stm.Add(&WorkingMemoryItem{Topic: "normal", Keywords: []string{"x"}, Activation: 1.0})
+	stm.Add(&WorkingMemoryItem{Topic: "emotional", Keywords: []string{"y"}, Activation: 1.0, Emotional: true})
+	if got := stm.Items()[0].LastAccessed; !got.Equal(start) {
+		t.Fatalf("Add should stamp LastAccessed from the clock, got %v", got)
+	}
+
+	// Same turn, ten hours later: 0.02/h vs 0.01/h.
+	now = start.Add(10 * time.Hour)
+	stm.Update(0, "zzz", nil)
+	for _, it := range stm.Items() {
+		want := map[string]float64{"normal": 0.8, "emotional": 0.9}[it.Topic]
+		if math.Abs(it.Activation-want) > 1e-9 || !it.LastAccessed.Equal(now) {
+			t.Errorf("%s: activation %f at %v, want %f at %v", it.Topic, it.Activation, it.LastAccessed, want, now)
+		}
+	}
+
+	// After a three-day pause only the emotional item lingers.
+	now = now.Add(72 * time.Hour)
+	stm.Update(1, "zzz", nil)
+	if items := stm.Items(); len(items) != 1 || items[0].Topic != "emotional" {
+		t.Errorf("expected only the emotional item to survive, got %+v", items)
+	}
+}
+
+func TestSTM_TimeDecayGranularity(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	config := DefaultSTMConfig()
+	config.TimeDecayRate = 0.6 // 0.01 per minute
+	stm := NewSTM(config)
+	stm.SetItems([]*WorkingMemoryItem{{Topic: "A", Keywords: []string{"a"}, Activation: 1.0, LastAccessed: start}})
+	decays := 0
+	stm.SetHooks(STMHooks{OnDecay: func(WorkingMemoryItem, float64) { decays++ }})
+
+	// Five Updates 20 seconds apart in the same turn: nothing is charged
+	// until a minute has passed, and then the whole minute is.
+	for i := 1; i <= 5; i++ {
+		stm.UpdateAt(0, start.Add(time.Duration(i)*20*time.Second), "zzz", nil)
+	}
+	if got := stm.Items()[0].Activation; math.Abs(got-0.99) > 1e-9 {
+		t.Errorf("expected one minute of decay (0.99), got %f", got)
+	}
+	if decays != 1 {
+		t.Errorf("expected a single OnDecay, got %d", decays)
+	}
+}
+
+func TestSTM_NoTimeDecayByDefault(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	stm := NewSTM(DefaultSTMConfig())
+	stm.SetItems([]*WorkingMemoryItem{{Topic: "A", Keywords: []string{"a"}, Activation: 1.0, LastAccessed: start}})
+	stm.UpdateAt(0, start.Add(72*time.Hour), "zzz", nil)
+	if got := stm.Items()[0].Activation; got != 1.0 {
+		t.Errorf("wall-clock decay should be opt-in, got %f", got)
+	}
+}
+
+func TestSTM_UpdateAtCombinesTurnsAndTime(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	config := DefaultSTMConfig()
+	config.TimeDecayRate = 0.02
+	stm := NewSTM(config)
+	stm.SetItems([]*WorkingMemoryItem{
+		{Topic: "A", Keywords: []string{"a"}, Activation: 1.0, LastAccessed: start},
+		{Topic: "B", Keywords: []string{"b"}, Activation: 1.0}, // clock not started
+	})
+
+	stm.UpdateAt(2, start.Add(5*time.Hour), "zzz", nil)
+	items := stm.Items()
+	// A: 2 turns * 0.15 + 5h * 0.02 = 0.4; B: turns only.
+	if math.Abs(items[0].Activation-0.6) > 1e-9 {
+		t.Errorf("A: expected activation 0.6, got %f", items[0].Activation)
+	}
+	if math.Abs(items[1].Activation-0.7) > 1e-9 || !items[1].LastAccessed.Equal(start.Add(5*time.Hour)) {
+		t.Errorf("B: expected activation 0.7 with its clock started, got %+v", items[1])
+	}
+}
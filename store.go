@@ -1,6 +1,14 @@
 package memai
 
-import "context"
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrUnsupported is returned when an operation needs an optional store
+// capability that the configured MemoryStore does not implement.
+var ErrUnsupported = errors.New("memai: operation not supported by store")
 
 // MemoryStore is the interface for long-term memory persistence.
 // Implementations can use SQLite, PostgreSQL, or any other backend.
@@ -21,3 +29,170 @@ type MemoryStore[ID comparable] interface {
 // EmbeddingFunc generates an embedding vector for the given text.
 // This decouples the memory system from any specific embedding provider.
 type EmbeddingFunc func(ctx context.Context, text string) ([]float64, error)
+
+// EmbeddingUpdater is an optional MemoryStore capability for rewriting the
+// embedding of an existing memory in place. It is required by LTM.Reembed.
+type EmbeddingUpdater[ID comparable] interface {
+	// UpdateEmbedding replaces the embedding of a memory and records the
+	// model that produced it. The dimension is len(embedding).
+	UpdateEmbedding(ctx context.Context, id ID, embedding []float64, model string) error
+}
+
+// ContentUpdater is an optional MemoryStore capability for rewriting the
+// content of an existing memory in place. It is required by
+// EncryptingStore.Reencrypt.
+type ContentUpdater[ID comparable] interface {
+	UpdateContent(ctx context.Context, id ID, content string) error
+}
+
+// NamespaceLookup is an optional MemoryStore capability for finding the
+// namespace of specific memories. With it, a namespaced LTM checks that the
+// IDs passed to ApplyFeedback, Delete, Restore and Link belong to its namespace
+// without loading every memory.
+type NamespaceLookup[ID comparable] interface {
+	// LookupNamespaces returns the namespace of each memory in ids,
+	// including soft-deleted ones. Unknown IDs are left out.
+	LookupNamespaces(ctx context.Context, ids []ID) (map[ID]Namespace, error)
+}
+
+// SoftDeleteStore is an optional MemoryStore capability for reversible
+// deletion. With it, LTM.Delete leaves a tombstone that Search ignores,
+// LTM.Restore undoes the deletion and LTM.Purge removes tombstones for good.
+// GetMemories must keep returning tombstoned memories, with DeletedAt set.
+type SoftDeleteStore[ID comparable] interface {
+	// SetDeleted records when a memory was deleted. A zero time restores it.
+	SetDeleted(ctx context.Context, id ID, at time.Time) error
+}
+
+// AccessStore is an optional MemoryStore capability for recording which
+// memories are actually recalled. When LTMConfig.TrackAccess is set, LTM.Search
+// hands it one batch of events per search, asynchronously.
+type AccessStore[ID comparable] interface {
+	// RecordAccess persists access events. Implementations should also set
+	// LastAccessed and increment AccessCount of the referenced memories.
+	RecordAccess(ctx context.Context, events []AccessEvent[ID]) error
+}
+
+// GraphStore is an optional MemoryStore capability for associative links
+// between memories. It enables LTM.Link, automatic thread/co-recall links and
+// spreading-activation retrieval. Links are treated as undirected.
+type GraphStore[ID comparable] interface {
+	// AddLinks stores links. Adding a link that already exists (same From,
+	// To and Kind) replaces its weight.
+	AddLinks(ctx context.Context, links []MemoryLink[ID]) error
+
+	// Links returns every link with either end in ids.
+	Links(ctx context.Context, ids []ID) ([]MemoryLink[ID], error)
+
+	// DeleteLinks removes every link touching id.
+	DeleteLinks(ctx context.Context, id ID) error
+}
+
+// storeDecorator forwards MemoryStore and every optional capability to inner,
+// returning ErrUnsupported for capabilities inner lacks. Store decorators
+// embed it and override the methods they change, so wrapping a store keeps
+// its capabilities.
+type storeDecorator[ID comparable] struct {
+	inner MemoryStore[ID]
+}
+
+// Unwrap returns the wrapped store.
+func (d storeDecorator[ID]) Unwrap() MemoryStore[ID] {
+	return d.inner
+}
+
+// capability returns store as C when it implements C. A decorator with an
+// Unwrap method only counts when the store it wraps supports C too.
+func capability[C any, ID comparable](store MemoryStore[ID]) (C, bool) {
+	c, ok := store.(C)
+	if !ok {
+		return c, false
+	}
+	if u, ok := store.(interface{ Unwrap() MemoryStore[ID] }); ok {
+		if _, ok := capability[C](u.Unwrap()); !ok {
+			var zero C
+			return zero, false
+		}
+	}
+	return c, true
+}
+
+func (d storeDecorator[ID]) GetMemories(ctx context.Context) ([]Memory[ID], error) {
+	return d.inner.GetMemories(ctx)
+}
+
+func (d storeDecorator[ID]) SaveMemory(ctx context.Context, mem *Memory[ID]) error {
+	return d.inner.SaveMemory(ctx, mem)
+}
+
+func (d storeDecorator[ID]) DeleteMemory(ctx context.Context, id ID) error {
+	return d.inner.DeleteMemory(ctx, id)
+}
+
+func (d storeDecorator[ID]) UpdateBoost(ctx context.Context, id ID, delta float64) error {
+	return d.inner.UpdateBoost(ctx, id, delta)
+}
+
+func (d storeDecorator[ID]) UpdateEmbedding(ctx context.Context, id ID, embedding []float64, model string) error {
+	u, ok := d.inner.(EmbeddingUpdater[ID])
+	if !ok {
+		return ErrUnsupported
+	}
+	return u.UpdateEmbedding(ctx, id, embedding, model)
+}
+
+func (d storeDecorator[ID]) UpdateContent(ctx context.Context, id ID, content string) error {
+	u, ok := d.inner.(ContentUpdater[ID])
+	if !ok {
+		return ErrUnsupported
+	}
+	return u.UpdateContent(ctx, id, content)
+}
+
+func (d storeDecorator[ID]) LookupNamespaces(ctx context.Context, ids []ID) (map[ID]Namespace, error) {
+	n, ok := d.inner.(NamespaceLookup[ID])
+	if !ok {
+		return nil, ErrUnsupported
+	}
+	return n.LookupNamespaces(ctx, ids)
+}
+
+func (d storeDecorator[ID]) SetDeleted(ctx context.Context, id ID, at time.Time) error {
+	s, ok := d.inner.(SoftDeleteStore[ID])
+	if !ok {
+		return ErrUnsupported
+	}
+	return s.SetDeleted(ctx, id, at)
+}
+
+func (d storeDecorator[ID]) RecordAccess(ctx context.Context, events []AccessEvent[ID]) error {
+	a, ok := d.inner.(AccessStore[ID])
+	if !ok {
+		return ErrUnsupported
+	}
+	return a.RecordAccess(ctx, events)
+}
+
+func (d storeDecorator[ID]) AddLinks(ctx context.Context, links []MemoryLink[ID]) error {
+	g, ok := d.inner.(GraphStore[ID])
+	if !ok {
+		return ErrUnsupported
+	}
+	return g.AddLinks(ctx, links)
+}
+
+func (d storeDecorator[ID]) Links(ctx context.Context, ids []ID) ([]MemoryLink[ID], error) {
+	g, ok := d.inner.(GraphStore[ID])
+	if !ok {
+		return nil, ErrUnsupported
+	}
+	return g.Links(ctx, ids)
+}
+
+func (d storeDecorator[ID]) DeleteLinks(ctx context.Context, id ID) error {
+	g, ok := d.inner.(GraphStore[ID])
+	if !ok {
+		return ErrUnsupported
+	}
+	return g.DeleteLinks(ctx, id)
+}
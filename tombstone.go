@@ -0,0 +1,96 @@
+package memai
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Restore undoes a soft delete. The store must implement SoftDeleteStore.
+// When the LTM or ctx is bound to a namespace, the memory must belong to it.
+func (l *LTM[ID]) Restore(ctx context.Context, id ID) error {
+	if l.softDelete == nil {
+		return fmt.Errorf("restore: %w (SoftDeleteStore)", ErrUnsupported)
+	}
+	ctx, ns, err := l.resolveNamespace(ctx, Namespace{})
+	if err != nil {
+		return err
+	}
+	if err := l.checkOwned(ctx, ns, []ID{id}); err != nil {
+		return err
+	}
+	if err := l.softDelete.SetDeleted(ctx, id, time.Time{}); err != nil {
+		return fmt.Errorf("memory store error: %w", err)
+	}
+	return nil
+}
+
+// Trash returns the soft-deleted memories of the namespace, most recently
+// deleted first, e.g. to offer an undo.
+func (l *LTM[ID]) Trash(ctx context.Context) ([]Memory[ID], error) {
+	ctx, ns, err := l.resolveNamespace(ctx, Namespace{})
+	if err != nil {
+		return nil, err
+	}
+	memories, err := l.scopedMemories(ctx, ns, true)
+	if err != nil {
+		return nil, fmt.Errorf("memory store error: %w", err)
+	}
+	var trash []Memory[ID]
+	for _, mem := range memories {
+		if !mem.DeletedAt.IsZero() {
+			trash = append(trash, mem)
+		}
+	}
+	slices.SortStableFunc(trash, func(a, b Memory[ID]) int {
+		return b.DeletedAt.Compare(a.DeletedAt)
+	})
+	return trash, nil
+}
+
+// Purge permanently removes memories that were soft-deleted at least
+// retention ago, along with their graph links and cached data, and returns
+// how many it removed. A retention of 0 empties the trash. Purge only
+// touches the namespace of the LTM or ctx.
+func (l *LTM[ID]) Purge(ctx context.Context, retention time.Duration) (int, error) {
+	ctx, ns, err := l.resolveNamespace(ctx, Namespace{})
+	if err != nil {
+		return 0, err
+	}
+	memories, err := l.scopedMemories(ctx, ns, true)
+	if err != nil {
+		return 0, fmt.Errorf("memory store error: %w", err)
+	}
+	cutoff := l.now().Add(-retention)
+	// Collect first: the store may hand back its own slice, which deleting
+	// would shift under the loop.
+	var expired []ID
+	for _, mem := range memories {
+		if !mem.DeletedAt.IsZero() && !mem.DeletedAt.After(cutoff) {
+			expired = append(expired, mem.ID)
+		}
+	}
+	for i, id := range expired {
+		if err := ctx.Err(); err != nil {
+			return i, err
+		}
+		if err := l.hardDelete(ctx, id); err != nil {
+			return i, err
+		}
+	}
+	return len(expired), nil
+}
+
+// hardDelete removes a memory from the store and its links from the graph.
+func (l *LTM[ID]) hardDelete(ctx context.Context, id ID) error {
+	if err := l.store.DeleteMemory(ctx, id); err != nil {
+		return fmt.Errorf("memory store error: %w", err)
+	}
+	if l.graph != nil {
+		if err := l.graph.DeleteLinks(ctx, id); err != nil {
+			return fmt.Errorf("graph store error: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package memai
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// softStore is a graphStore that implements SoftDeleteStore.
+type softStore struct {
+	graphStore
+}
+
+func (s *softStore) SetDeleted(_ context.Context, id int, at time.Time) error {
+	for i := range s.memories {
+		if s.memories[i].ID == id {
+			s.memories[i].DeletedAt = at
+		}
+	}
+	return nil
+}
+
+func TestLTM_SoftDeleteAndRestore(t *testing.T) {
+	store := &softStore{}
+	store.memories = []Memory[int]{
+		{ID: 1, Content: "keep", Embedding: []float64{1, 0}},
+		{ID: 2, Content: "forget me", Embedding: []float64{1, 0}},
+	}
+	ltm := NewLTM[int](store, nil, DefaultLTMConfig())
+	ctx := context.Background()
+	q := SearchQuery{QueryEmbedding: []float64{1, 0}}
+
+	if err := ltm.Delete(ctx, 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(store.memories) != 2 || store.memories[1].DeletedAt.IsZero() {
+		t.Fatalf("memory should be tombstoned, not removed: %+v", store.memories)
+	}
+	results, _ := ltm.Search(ctx, q)
+	if len(results) != 1 || results[0].Memory.ID != 1 {
+		t.Errorf("search should skip tombstones, got %+v", results)
+	}
+	trash, err := ltm.Trash(ctx)
+	if err != nil || len(trash) != 1 || trash[0].ID != 2 {
+		t.Errorf("unexpected trash: %+v, %v", trash, err)
+	}
+
+	if err := ltm.Restore(ctx, 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if results, _ := ltm.Search(ctx, q); len(results) != 2 {
+		t.Errorf("restored memory should be searchable, got %d results", len(results))
+	}
+}
+
+func TestLTM_PurgeAfterRetention(t *testing.T) {
+	now := time.Date(2026, 6, 17, 12, 0, 0, 0, time.UTC)
+	store := &softStore{}
+	store.memories = []Memory[int]{
+		{ID: 1, DeletedAt: now.Add(-48 * time.Hour)},
+		{ID: 2, DeletedAt: now.Add(-time.Hour)},
+		{ID: 3},
+	}
+	store.links = []MemoryLink[int]{{From: 1, To: 3}, {From: 2, To: 3}}
+	cfg := DefaultLTMConfig()
+	cfg.Clock = func() time.Time { return now }
+	ltm := NewLTM[int](store, nil, cfg)
+
+	n, err := ltm.Purge(context.Background(), 24*time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 1 || len(store.memories) != 2 || store.memories[0].ID != 2 {
+		t.Errorf("expected only memory 1 purged, got %d and %+v", n, store.memories)
+	}
+	if len(store.links) != 1 || store.links[0].From != 2 {
+		t.Errorf("links of the purged memory should be deleted: %+v", store.links)
+	}
+}
+
+func TestLTM_DeleteWithoutSoftDeleteIsHard(t *testing.T) {
+	store := &graphStore{}
+	store.memories = []Memory[int]{{ID: 1}, {ID: 2}}
+	store.links = []MemoryLink[int]{{From: 1, To: 2}}
+	ltm := NewLTM[int](store, nil, DefaultLTMConfig())
+
+	if err := ltm.Delete(context.Background(), 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(store.memories) != 1 || len(store.links) != 0 {
+		t.Errorf("expected memory and links removed: %+v %+v", store.memories, store.links)
+	}
+	if err := ltm.Restore(context.Background(), 1); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported, got %v", err)
+	}
+}
+
+func TestLTM_RestoreChecksNamespace(t *testing.T) {
+	alice := Namespace{User: "alice"}
+	store := &softStore{}
+	store.memories = []Memory[int]{{ID: 1, Namespace: Namespace{User: "bob"}, DeletedAt: time.Now()}}
+	ltm := NewLTM[int](store, nil, DefaultLTMConfig())
+
+	err := ltm.Restore(WithNamespace(context.Background(), alice), 1)
+	if !errors.Is(err, ErrNamespaceMismatch) {
+		t.Errorf("expected ErrNamespaceMismatch, got %v", err)
+	}
+}
@@ -0,0 +1,302 @@
+package memai
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TopicExtractor turns a message into candidate working memory items, each
+// with a Topic phrase and its Keywords. Implement this interface to plug in
+// a keyphrase model or an LLM.
+type TopicExtractor interface {
+	ExtractTopics(message string) []WorkingMemoryItem
+}
+
+// maxTopicsPerMessage bounds the candidates taken from one message, so a
+// long message cannot flood working memory and evict everything else.
+const maxTopicsPerMessage = 3
+
+// maxTopicWords bounds an English topic phrase; the head noun comes last,
+// so longer phrases keep their final words.
+const maxTopicWords = 3
+
+// topicStopwordsJA are kanji and katakana words that carry no topic of
+// their own: deictic time words, pronouns and fillers.
+var topicStopwordsJA = map[string]bool{
+	"今日": true, "明日": true, "昨日": true, "今回": true, "今度": true, "最近": true,
+	"今週": true, "来週": true, "先週": true, "今月": true, "来月": true, "先月": true,
+	"今年": true, "来年": true, "去年": true,
+	"自分": true, "本当": true, "一緒": true, "全部": true, "大丈夫": true, "何": true,
+	"私": true, "僕": true, "俺": true, "彼": true, "彼女": true, "皆": true,
+	"今": true, "前": true, "後": true, "時": true, "事": true, "方": true,
+	"ちょっと": true, "ホント": true, "マジ": true,
+}
+
+// topicStopwordsEN are English words that never start, end or belong to a
+// topic phrase: function words, pronouns, auxiliaries, common verbs and
+// time words. Days and months come from capitalStopwordsEN.
+var topicStopwordsEN = map[string]bool{
+	"a": true, "an": true, "the": true, "this": true, "that": true, "these": true, "those": true,
+	"i": true, "me": true, "my": true, "we": true, "us": true, "our": true, "you": true, "your": true,
+	"he": true, "him": true, "his": true, "she": true, "her": true, "it": true, "its": true,
+	"they": true, "them": true, "their": true, "i'm": true, "i've": true, "i'll": true, "i'd": true,
+	"it's": true, "that's": true, "let's": true, "don't": true, "can't": true, "won't": true,
+	"is": true, "are": true, "was": true, "were": true, "be": true, "been": true, "being": true,
+	"am": true, "do": true, "does": true, "did": true, "have": true, "has": true, "had": true,
+	"will": true, "would": true, "can": true, "could": true, "should": true, "must": true,
+	"might": true, "may": true, "shall": true,
+	"and": true, "or": true, "but": true, "so": true, "if": true, "then": true, "than": true,
+	"because": true, "while": true, "about": true, "of": true, "to": true, "in": true, "on": true,
+	"at": true, "by": true, "for": true, "with": true, "from": true, "into": true, "over": true,
+	"after": true, "before": true, "up": true, "down": true, "out": true, "off": true,
+	"what": true, "when": true, "where": true, "why": true, "how": true, "who": true, "which": true,
+	"not": true, "no": true, "yes": true, "very": true, "really": true, "just": true, "also": true,
+	"too": true, "still": true, "again": true, "some": true, "any": true, "all": true, "more": true,
+	"much": true, "many": true, "there": true, "here": true, "now": true, "today": true,
+	"tomorrow": true, "yesterday": true, "tonight": true, "hi": true, "hello": true, "hey": true,
+	"ok": true, "okay": true, "thanks": true, "thank": true, "please": true, "sorry": true,
+	"get": true, "got": true, "go": true, "going": true, "want": true, "need": true, "think": true,
+	"know": true, "like": true, "feel": true, "make": true, "let": true, "tell": true, "said": true,
+	"say": true, "see": true, "look": true, "try": true, "thing": true, "things": true,
+	"something": true, "anything": true, "everything": true, "lot": true, "bit": true,
+	"next": true, "last": true, "day": true, "week": true, "month": true, "year": true,
+	"morning": true, "evening": true, "night": true, "time": true,
+}
+
+// verbCuesEN are words after which the next word is a verb or a predicate,
+// not a topic: subject pronouns, the infinitive marker, modals and copulas
+// ("I love ...", "to finish ...", "we should book ...", "I'm nervous").
+var verbCuesEN = map[string]bool{
+	"i": true, "we": true, "you": true, "they": true, "he": true, "she": true, "to": true,
+	"will": true, "would": true, "can": true, "could": true, "should": true, "must": true,
+	"might": true, "may": true, "i'll": true, "let's": true, "don't": true, "didn't": true,
+	"can't": true, "won't": true, "i'm": true, "it's": true, "is": true, "are": true,
+	"was": true, "were": true, "am": true, "be": true, "feel": true, "felt": true, "seems": true,
+}
+
+// HeuristicTopicExtractor implements TopicExtractor with rules for Japanese
+// and English text, no model required.
+//
+// Japanese text is segmented by character class. Runs of kanji, katakana
+// and Latin letters form noun phrases, which may be joined by の
+// (京都の旅行). Runs of hiragana separate them. English text yields noun
+// phrases: runs of content words between stopwords, skipping the verb after
+// a subject pronoun, "to" or a modal ("I need to finish the quarterly
+// report" -> quarterly report).
+//
+// At most three candidates are returned per message, in order of first
+// appearance.
+type HeuristicTopicExtractor struct{}
+
+// NewHeuristicTopicExtractor returns the built-in rule-based extractor.
+func NewHeuristicTopicExtractor() *HeuristicTopicExtractor {
+	return &HeuristicTopicExtractor{}
+}
+
+// ExtractTopics implements TopicExtractor. Keywords are the parts of the
+// topic phrase worth matching on later turns; English keywords are
+// lowercased.
+func (e *HeuristicTopicExtractor) ExtractTopics(message string) []WorkingMemoryItem {
+	var phrases [][]string
+	if containsJapanese(message) {
+		phrases = japanesePhrases([]rune(message))
+	} else {
+		phrases = englishPhrases([]rune(message))
+	}
+
+	var out []WorkingMemoryItem
+	seen := make(map[string]bool)
+	for _, words := range phrases {
+		topic := topicFromWords(words)
+		if topic == "" || seen[strings.ToLower(topic)] {
+			continue
+		}
+		seen[strings.ToLower(topic)] = true
+		out = append(out, WorkingMemoryItem{Topic: topic, Keywords: topicKeywords(words)})
+		if len(out) == maxTopicsPerMessage {
+			break
+		}
+	}
+	return out
+}
+
+// topicFromWords joins phrase parts: Japanese parts directly (the の
+// particle is its own part), English words with spaces.
+func topicFromWords(words []string) string {
+	if len(words) == 0 {
+		return ""
+	}
+	if containsJapanese(strings.Join(words, "")) {
+		return strings.Join(words, "")
+	}
+	return strings.Join(words, " ")
+}
+
+// topicKeywords returns the parts of a phrase that identify it: Japanese
+// parts of two or more runes, English words of three or more letters.
+func topicKeywords(words []string) []string {
+	var out []string
+	for _, w := range words {
+		if w == "の" || topicStopwordsJA[w] {
+			continue
+		}
+		n := utf8.RuneCountInString(w)
+		if containsJapanese(w) && n >= 2 || !containsJapanese(w) && n >= 3 {
+			out = append(out, strings.ToLower(w))
+		}
+	}
+	return out
+}
+
+// runeClass is the character class used by the Japanese segmenter.
+type runeClass int
+
+const (
+	classOther runeClass = iota
+	classHiragana
+	classKanji
+	classKatakana
+	classLatin
+)
+
+func classOf(r rune) runeClass {
+	switch {
+	case unicode.Is(unicode.Han, r) || r == '々':
+		return classKanji
+	case isKatakana(r):
+		return classKatakana
+	case unicode.Is(unicode.Hiragana, r):
+		return classHiragana
+	case isLatinLetter(r) || unicode.IsDigit(r):
+		return classLatin
+	}
+	return classOther
+}
+
+// japanesePhrases segments runes by character class and groups adjacent
+// noun segments (kanji, katakana, Latin) into phrases. A lone の between two
+// noun segments joins them. Stopword segments end a phrase, and phrases
+// made only of a single kanji (a verb or adjective stem such as 行き) are
+// dropped.
+func japanesePhrases(runes []rune) [][]string {
+	type segment struct {
+		text  string
+		class runeClass
+	}
+	var segs []segment
+	for i := 0; i < len(runes); {
+		c := classOf(runes[i])
+		j := i + 1
+		for j < len(runes) && classOf(runes[j]) == c {
+			j++
+		}
+		segs = append(segs, segment{string(runes[i:j]), c})
+		i = j
+	}
+
+	isNoun := func(s segment) bool {
+		if s.class != classKanji && s.class != classKatakana && s.class != classLatin {
+			return false
+		}
+		return !topicStopwordsJA[s.text] && strings.Trim(s.text, "ー・") != ""
+	}
+
+	var phrases [][]string
+	var cur []string
+	flush := func() {
+		if len(cur) == 1 && utf8.RuneCountInString(cur[0]) < 2 {
+			cur = nil
+		}
+		if len(cur) > 0 {
+			phrases = append(phrases, cur)
+		}
+		cur = nil
+	}
+	for i, s := range segs {
+		switch {
+		case isNoun(s):
+			cur = append(cur, s.text)
+		case s.text == "の" && len(cur) > 0 && i+1 < len(segs) && isNoun(segs[i+1]):
+			cur = append(cur, s.text)
+		case s.class == classOther && s.text == " " && len(cur) > 0 && segs[i-1].class == classLatin &&
+			i+1 < len(segs) && segs[i+1].class == classLatin:
+			// Keep Latin words of a name together (Google Cloud).
+			cur = append(cur, s.text)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return phrases
+}
+
+// englishPhrases returns runs of content words between stopwords,
+// punctuation and verbs, keeping the last maxTopicWords words of each run.
+// Trailing words ending in -ly are adverbs and dropped; before a noun they
+// are adjectives and kept (quarterly report). Bare numbers are not content
+// words.
+func englishPhrases(runes []rune) [][]string {
+	var phrases [][]string
+	var cur []string
+	flush := func() {
+		for len(cur) > 0 && strings.HasSuffix(strings.ToLower(cur[len(cur)-1]), "ly") {
+			cur = cur[:len(cur)-1]
+		}
+		if len(cur) > maxTopicWords {
+			cur = cur[len(cur)-maxTopicWords:]
+		}
+		for _, w := range cur {
+			if utf8.RuneCountInString(w) >= 3 {
+				phrases = append(phrases, cur)
+				break
+			}
+		}
+		cur = nil
+	}
+
+	prev := ""
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		if !isLatinLetter(r) && !unicode.IsDigit(r) {
+			if r != ' ' {
+				flush()
+				prev = ""
+			}
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && (isLatinLetter(runes[j]) || unicode.IsDigit(runes[j]) ||
+			((runes[j] == '\'' || runes[j] == '’' || runes[j] == '-') && j+1 < len(runes) && isLatinLetter(runes[j+1]))) {
+			j++
+		}
+		word := strings.ReplaceAll(string(runes[i:j]), "’", "'")
+		i = j
+		lower := strings.ToLower(word)
+		if w, ok := strings.CutSuffix(word, "'s"); ok {
+			word, lower = w, strings.TrimSuffix(lower, "'s")
+		}
+
+		content := !topicStopwordsEN[lower] && !capitalStopwordsEN[lower] && !verbCuesEN[prev] &&
+			strings.IndexFunc(lower, isLatinLetter) >= 0
+		if content {
+			cur = append(cur, word)
+		} else {
+			flush()
+		}
+		prev = lower
+	}
+	flush()
+	return phrases
+}
+
+// containsJapanese reports whether s contains kana or kanji.
+func containsJapanese(s string) bool {
+	for _, r := range s {
+		if c := classOf(r); c == classHiragana || c == classKanji || c == classKatakana && r != '・' {
+			return true
+		}
+	}
+	return false
+}
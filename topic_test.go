@@ -0,0 +1,66 @@
+package memai
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHeuristicTopicExtractor_Japanese(t *testing.T) {
+	e := NewHeuristicTopicExtractor()
+	tests := []struct {
+		message string
+		want    []WorkingMemoryItem
+	}{
+		{
+			"京都の旅行の予定を立てたいんだけど、ホテルがまだ決まってない",
+			[]WorkingMemoryItem{
+				{Topic: "京都の旅行の予定", Keywords: []string{"京都", "旅行", "予定"}},
+				{Topic: "ホテル", Keywords: []string{"ホテル"}},
+			},
+		},
+		{
+			// Stopwords and verb stems (行き) are not topics.
+			"明日のプロジェクト会議、行きますか？",
+			[]WorkingMemoryItem{{Topic: "プロジェクト会議", Keywords: []string{"プロジェクト", "会議"}}},
+		},
+		{
+			"Google Cloudの料金が高い",
+			[]WorkingMemoryItem{{Topic: "Google Cloudの料金", Keywords: []string{"google", "cloud", "料金"}}},
+		},
+		{"すごーい！", nil},
+	}
+	for _, tt := range tests {
+		if got := e.ExtractTopics(tt.message); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ExtractTopics(%q) = %+v, want %+v", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestHeuristicTopicExtractor_English(t *testing.T) {
+	e := NewHeuristicTopicExtractor()
+	tests := []struct {
+		message string
+		want    []string
+	}{
+		{"I need to finish the quarterly report by Friday.", []string{"quarterly report"}},
+		{"My sister's wedding is next month and I'm nervous", []string{"sister wedding"}},
+		{"We should book a hotel in Kyoto for the cherry blossom trip", []string{"hotel", "Kyoto", "cherry blossom trip"}},
+		{"ok thanks!", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, it := range e.ExtractTopics(tt.message) {
+			got = append(got, it.Topic)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ExtractTopics(%q) topics = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestHeuristicTopicExtractor_LimitsCandidates(t *testing.T) {
+	got := NewHeuristicTopicExtractor().ExtractTopics("天気、仕事、旅行、映画、音楽の話")
+	if len(got) != maxTopicsPerMessage {
+		t.Errorf("expected %d candidates, got %+v", maxTopicsPerMessage, got)
+	}
+}
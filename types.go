@@ -1,6 +1,9 @@
 package memai
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Language specifies the language used for keyword-based emotion analysis.
 type Language string
@@ -30,20 +33,33 @@ const (
 
 // EmotionalState represents the detected emotional context of a message.
 type EmotionalState struct {
-	Primary   EmotionType
-	Intensity float64 // 0.0 - 1.0
-	Valence   float64 // -1.0 to 1.0
+	Primary   EmotionType `json:"primary"`
+	Intensity float64     `json:"intensity"` // 0.0 - 1.0
+	Valence   float64     `json:"valence"`   // -1.0 to 1.0
 }
 
 // WorkingMemoryItem is an active item in short-term memory.
 type WorkingMemoryItem struct {
-	Topic        string
-	Content      string
-	Keywords     []string
-	Activation   float64 // 0.0 - 1.0
-	TurnCreated  int
-	TurnAccessed int
-	Emotional    bool
+	Topic        string   `json:"topic"`
+	Content      string   `json:"content,omitempty"`
+	Keywords     []string `json:"keywords,omitempty"`
+	Activation   float64  `json:"activation"` // 0.0 - 1.0
+	TurnCreated  int      `json:"turn_created"`
+	TurnAccessed int      `json:"turn_accessed"`
+	Emotional    bool     `json:"emotional,omitempty"`
+	// LastAccessed is when the item was added or last decayed; time-based
+	// decay runs from here. Zero until the STM first sees the item.
+	LastAccessed time.Time `json:"last_accessed,omitzero"`
+
+	// Decay state kept by STM for its DecayFunc; see DecayInput.
+	Strength      float64 `json:"strength,omitempty"`      // Activation when last added or refreshed
+	Age           float64 `json:"age,omitempty"`           // Load since last added or refreshed
+	Lifetime      float64 `json:"lifetime,omitempty"`      // Load since added
+	Presentations int     `json:"presentations,omitempty"` // Times added or refreshed
+
+	// TopicEmbedding caches the embedding of Topic for semantic refresh
+	// (STM.UpdateContext). It is computed on first use.
+	TopicEmbedding []float64 `json:"topic_embedding,omitempty"`
 }
 
 // Memory represents a stored long-term memory with its embedding.
@@ -56,12 +72,59 @@ type Memory[ID comparable] struct {
 	EventDate          string
 	Boost              float64
 	EmotionalIntensity float64
+	EmbeddingModel     string // ID of the model that produced Embedding ("" = unknown)
+	EmbeddingDim       int    // Dimension of Embedding when it was generated (0 = unknown)
+	Namespace          Namespace
+	LastAccessed       time.Time  // Last time the memory was returned by a search (zero = never)
+	AccessCount        int        // Number of times the memory was returned by a search
+	Entities           []string   // People, places and projects mentioned (see EntityExtractor)
+	Kind               MemoryKind // Episodic event or semantic fact ("" = KindEpisodic)
+	CreatedAt          time.Time  // When the memory was saved (set by LTM.Save when zero)
+	Importance         float64    // 0.0 - 1.0 (see ImportanceEstimator)
+	Provenance         Provenance // Where the memory came from
+	DeletedAt          time.Time  // When the memory was soft-deleted (zero = live; see SoftDeleteStore)
 }
 
+// SourceType identifies what a memory was derived from.
+type SourceType string
+
+const (
+	SourceUserMessage   SourceType = "user_message"  // Something the user said
+	SourceAgentMessage  SourceType = "agent_message" // Something the agent said
+	SourceDocument      SourceType = "document"      // An uploaded or retrieved document
+	SourceConsolidation SourceType = "consolidation" // Summarised from other memories
+)
+
+// Provenance records the origin of a memory, for citing it to the user and
+// tracing bad memories back to their source.
+type Provenance struct {
+	Source         SourceType
+	MessageID      string    // ID of the source message or document
+	Turn           int       // Conversation turn of the source message (0 = unknown)
+	ConversationID string    // Conversation the source belongs to
+	SourceTime     time.Time // When the source was said or written (zero = unknown)
+	Extractor      string    // Component or model that turned the source into a memory
+}
+
+// MemoryKind distinguishes events from lasting facts. Each kind is ranked
+// under its own KindPolicy (see LTMConfig.Kinds).
+type MemoryKind string
+
+const (
+	// KindEpisodic is an event tied to a time and conversation, e.g. "we
+	// argued about the deadline last Tuesday".
+	KindEpisodic MemoryKind = "episodic"
+	// KindSemantic is a fact that stays true regardless of when it was
+	// learned, e.g. "the user's birthday is May 3".
+	KindSemantic MemoryKind = "semantic"
+)
+
 // SearchResult represents a memory search result with computed score.
 type SearchResult[ID comparable] struct {
-	Memory Memory[ID]
-	Score  float64
+	Memory     Memory[ID]
+	Score      float64
+	Activation float64   // Spreading activation received from linked hits (0 for plain cosine hits)
+	SearchedAt time.Time // LTMConfig.Clock time of the search; Citation dates are relative to it
 }
 
 // SearchQuery holds the parameters for a long-term memory search.
@@ -73,4 +136,7 @@ type SearchQuery struct {
 	DateNegated        bool
 	DateMonthOnly      bool
 	EmotionalIntensity float64
+	Namespace          Namespace // Restricts the search to one namespace; zero uses the LTM's own
+	Entities           []string  // Entities in the query; extracted from Query when empty
+	RequireEntity      bool      // Only return memories sharing an entity with the query (ignored if it has none)
 }